@@ -24,9 +24,9 @@ func completerFunc(d prompt.Document) []prompt.Suggest {
 	t := d.GetWordBeforeCursor()
 	if strings.HasPrefix(t, "--") {
 		return []prompt.Suggest{
-			{"--foo", ""},
-			{"--bar", ""},
-			{"--baz", ""},
+			{Text: "--foo"},
+			{Text: "--bar"},
+			{Text: "--baz"},
 		}
 	}
 	return filePathCompleter.Complete(d)
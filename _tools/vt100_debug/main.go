@@ -11,11 +11,12 @@ import (
 )
 
 func main() {
-	if err := term.SetRaw(syscall.Stdin); err != nil {
+	state, err := term.SetRaw(syscall.Stdin)
+	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	defer term.Restore()
+	defer term.Restore(syscall.Stdin, state)
 
 	bufCh := make(chan []byte, 128)
 	go readBuffer(bufCh)
@@ -0,0 +1,195 @@
+package prompt
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Colors beyond the 16 ANSI ones are packed into Color with their kind in the high bits,
+// so that the ANSI constants keep their values.
+const (
+	colorKindMask Color = 0x3 << 24
+	color256Kind  Color = 0x1 << 24
+	colorRGBKind  Color = 0x2 << 24
+)
+
+// Color256 returns the color n of the xterm 256-color palette.
+func Color256(n uint8) Color {
+	return color256Kind | Color(n)
+}
+
+// ColorRGB returns a 24-bit true color.
+func ColorRGB(r, g, b uint8) Color {
+	return colorRGBKind | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// ColorDepth is the number of colors a terminal can display.
+type ColorDepth int
+
+const (
+	// ColorDepthAuto detects the color depth from the environment.
+	ColorDepthAuto ColorDepth = iota
+	// ColorDepth16 supports the 16 ANSI colors.
+	ColorDepth16
+	// ColorDepth256 supports the xterm 256-color palette.
+	ColorDepth256
+	// ColorDepthTrueColor supports 24-bit colors.
+	ColorDepthTrueColor
+)
+
+// DetectColorDepth guesses the color depth of the terminal from the COLORTERM and TERM environment variables.
+func DetectColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrueColor
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case strings.HasSuffix(term, "-direct") || strings.Contains(term, "truecolor"):
+		return ColorDepthTrueColor
+	case strings.Contains(term, "256color"):
+		return ColorDepth256
+	}
+	if runtime.GOOS == "windows" {
+		// WT_SESSION indicates Windows Terminal, which supports 24-bit colors.
+		if _, ok := os.LookupEnv("WT_SESSION"); ok {
+			return ColorDepthTrueColor
+		}
+	}
+	return ColorDepth16
+}
+
+// monochromeRequested reports whether the environment asks for output without colors,
+// either through NO_COLOR (see https://no-color.org) or with a dumb terminal.
+func monochromeRequested() bool {
+	if v, ok := os.LookupEnv("NO_COLOR"); ok && v != "" {
+		return true
+	}
+	return os.Getenv("TERM") == "dumb"
+}
+
+// convert returns the color nearest to c that can be displayed with depth.
+func (c Color) convert(depth ColorDepth) Color {
+	switch c & colorKindMask {
+	case colorRGBKind:
+		switch depth {
+		case ColorDepthTrueColor:
+			return c
+		case ColorDepth256:
+			return nearest256(c.rgb())
+		default:
+			return nearestANSI(c.rgb())
+		}
+	case color256Kind:
+		n := uint8(c &^ colorKindMask)
+		if depth == ColorDepth256 || depth == ColorDepthTrueColor {
+			return c
+		}
+		if n < 16 {
+			return Black + Color(n)
+		}
+		return nearestANSI(c.rgb())
+	}
+	return c
+}
+
+// rgb returns the red, green and blue components of c.
+// ANSI and palette colors use the xterm defaults.
+func (c Color) rgb() (r, g, b uint8) {
+	switch c & colorKindMask {
+	case colorRGBKind:
+		return uint8(c >> 16), uint8(c >> 8), uint8(c)
+	case color256Kind:
+		n := int(c &^ colorKindMask)
+		switch {
+		case n < 16:
+			return (Black + Color(n)).rgb()
+		case n < 232:
+			n -= 16
+			return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+		default:
+			v := uint8(8 + 10*(n-232))
+			return v, v, v
+		}
+	}
+	if c >= Black && c <= White {
+		p := ansiPalette[c-Black]
+		return p[0], p[1], p[2]
+	}
+	return 0, 0, 0
+}
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ansiPalette is the xterm default palette, in the order of the Color constants from Black to White.
+var ansiPalette = [16][3]uint8{
+	{0, 0, 0},
+	{205, 0, 0},
+	{0, 205, 0},
+	{205, 205, 0},
+	{0, 0, 238},
+	{205, 0, 205},
+	{0, 205, 205},
+	{229, 229, 229},
+	{127, 127, 127},
+	{255, 0, 0},
+	{0, 255, 0},
+	{255, 255, 0},
+	{92, 92, 255},
+	{255, 0, 255},
+	{0, 255, 255},
+	{255, 255, 255},
+}
+
+func nearestANSI(r, g, b uint8) Color {
+	best, bestDist := Black, -1
+	for i, p := range ansiPalette {
+		if d := colorDistance(r, g, b, p[0], p[1], p[2]); bestDist < 0 || d < bestDist {
+			best, bestDist = Black+Color(i), d
+		}
+	}
+	return best
+}
+
+func nearest256(r, g, b uint8) Color {
+	// Nearest color of the 6x6x6 cube.
+	ri, gi, bi := nearestCubeLevel(r), nearestCubeLevel(g), nearestCubeLevel(b)
+	cube := Color256(uint8(16 + 36*ri + 6*gi + bi))
+	cubeDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// Nearest color of the grayscale ramp.
+	avg := (int(r) + int(g) + int(b)) / 3
+	gi2 := (avg - 3) / 10
+	if gi2 < 0 {
+		gi2 = 0
+	} else if gi2 > 23 {
+		gi2 = 23
+	}
+	v := uint8(8 + 10*gi2)
+	if colorDistance(r, g, b, v, v, v) < cubeDist {
+		return Color256(uint8(232 + gi2))
+	}
+	return cube
+}
+
+func nearestCubeLevel(v uint8) int {
+	best, bestDist := 0, -1
+	for i, l := range cubeLevels {
+		d := int(v) - int(l)
+		if d < 0 {
+			d = -d
+		}
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr := int(r1) - int(r2)
+	dg := int(g1) - int(g2)
+	db := int(b1) - int(b2)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,87 @@
+package prompt
+
+import (
+	"os"
+	"testing"
+)
+
+func TestColorConvert(t *testing.T) {
+	scenarioTable := []struct {
+		color    Color
+		depth    ColorDepth
+		expected Color
+	}{
+		{color: Red, depth: ColorDepth16, expected: Red},
+		{color: Color256(196), depth: ColorDepth256, expected: Color256(196)},
+		{color: Color256(9), depth: ColorDepth16, expected: Red},
+		{color: Color256(196), depth: ColorDepth16, expected: Red},
+		{color: Color256(244), depth: ColorDepth16, expected: DarkGray},
+		{color: ColorRGB(255, 135, 0), depth: ColorDepthTrueColor, expected: ColorRGB(255, 135, 0)},
+		{color: ColorRGB(255, 135, 0), depth: ColorDepth256, expected: Color256(208)},
+		{color: ColorRGB(0, 0, 250), depth: ColorDepth16, expected: DarkBlue},
+		{color: ColorRGB(128, 128, 128), depth: ColorDepth256, expected: Color256(244)},
+	}
+
+	for _, s := range scenarioTable {
+		if actual := s.color.convert(s.depth); actual != s.expected {
+			t.Errorf("Should be %#x, but got %#x", s.expected, actual)
+		}
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	scenarioTable := []struct {
+		colorterm string
+		term      string
+		expected  ColorDepth
+	}{
+		{colorterm: "truecolor", term: "xterm", expected: ColorDepthTrueColor},
+		{term: "xterm-256color", expected: ColorDepth256},
+		{term: "xterm-direct", expected: ColorDepthTrueColor},
+		{term: "xterm", expected: ColorDepth16},
+	}
+
+	defer restoreEnv("COLORTERM")()
+	defer restoreEnv("TERM")()
+	for _, s := range scenarioTable {
+		os.Setenv("COLORTERM", s.colorterm)
+		os.Setenv("TERM", s.term)
+		if actual := DetectColorDepth(); actual != s.expected {
+			t.Errorf("Should be %d for COLORTERM=%q TERM=%q, but got %d", s.expected, s.colorterm, s.term, actual)
+		}
+	}
+}
+
+// restoreEnv returns a function restoring the environment variable key to its current value.
+func restoreEnv(key string) func() {
+	v, ok := os.LookupEnv(key)
+	return func() {
+		if ok {
+			os.Setenv(key, v)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestMonochromeRequested(t *testing.T) {
+	scenarioTable := []struct {
+		noColor  string
+		term     string
+		expected bool
+	}{
+		{noColor: "1", term: "xterm-256color", expected: true},
+		{noColor: "", term: "xterm-256color", expected: false},
+		{noColor: "", term: "dumb", expected: true},
+	}
+
+	defer restoreEnv("NO_COLOR")()
+	defer restoreEnv("TERM")()
+	for _, s := range scenarioTable {
+		os.Setenv("NO_COLOR", s.noColor)
+		os.Setenv("TERM", s.term)
+		if actual := monochromeRequested(); actual != s.expected {
+			t.Errorf("Should be %t for NO_COLOR=%q TERM=%q, but got %t", s.expected, s.noColor, s.term, actual)
+		}
+	}
+}
@@ -28,12 +28,22 @@ type Suggest struct {
 	Description    string
 	Placeholder    string
 	Metadata       interface{}
+	// Group puts the suggestion under a section header in the drop down.
+	// Suggestions sharing a group are listed together, in the order the groups first appear.
+	Group string
+	// Icon is a short marker drawn in front of Text, like "ƒ" for a function or "📁" for a directory.
+	Icon string
+	// Style overrides the colors and attributes of Text in the drop down.
+	// DefaultColor keeps the color of the drop down. When the row is selected only the attributes are used,
+	// so that a deprecated item can stay crossed out for example.
+	Style *Style
 }
 
 // CompletionManager manages which suggestion is now selected.
 type CompletionManager struct {
 	selected            int // -1 means nothing one is selected.
 	tmp                 []Suggest
+	headers             []bool // Whether each item of tmp is a section header. nil without groups.
 	max                 uint16
 	maxTextWidth        uint16
 	maxDescriptionWidth uint16
@@ -42,11 +52,17 @@ type CompletionManager struct {
 	verticalScroll int
 	wordSeparator  []string
 	showAtStart    bool
+
+	documentationLoader DocumentationLoader
+	documentation       string
+	documentationFor    int
+	documentationLoaded bool
+	documentationScroll int
 }
 
 // GetSelectedSuggestion returns the selected item.
 func (c *CompletionManager) GetSelectedSuggestion() (s Suggest, ok bool) {
-	if c.selected == -1 || c.selected >= len(c.tmp) {
+	if c.selected == -1 || c.selected >= len(c.tmp) || c.isHeader(c.selected) {
 		return Suggest{}, false
 	} else if c.selected < -1 {
 		debug.Assert(false, "must not reach here")
@@ -56,9 +72,23 @@ func (c *CompletionManager) GetSelectedSuggestion() (s Suggest, ok bool) {
 	return c.tmp[c.selected], true
 }
 
-// GetSuggestions returns the list of suggestion.
+// GetSuggestions returns the list of suggestion, without the section headers of the drop down.
 func (c *CompletionManager) GetSuggestions() []Suggest {
-	return c.tmp
+	if c.headers == nil {
+		return c.tmp
+	}
+	s := make([]Suggest, 0, len(c.tmp))
+	for i := range c.tmp {
+		if !c.headers[i] {
+			s = append(s, c.tmp[i])
+		}
+	}
+	return s
+}
+
+// isHeader returns whether the i-th item is a section header. Headers can't be selected.
+func (c *CompletionManager) isHeader(i int) bool {
+	return i >= 0 && i < len(c.headers) && c.headers[i]
 }
 
 // Reset to select nothing.
@@ -79,33 +109,96 @@ func (c *CompletionManager) Completer(in Document, promptCh chan []Suggest) {
 	c.completer(in, promptCh)
 }
 
+// SetResults replaces the suggestions, inserting section headers when they are grouped.
 func (c *CompletionManager) SetResults(suggests []Suggest) {
-	c.tmp = suggests
+	c.tmp, c.headers = groupSuggestions(suggests)
+	c.documentationLoaded = false
 }
 
 // Previous to select the previous suggestion item.
 func (c *CompletionManager) Previous() {
-	if c.verticalScroll == c.selected && c.selected > 0 {
-		c.verticalScroll--
-	}
-	c.selected--
-	c.update()
+	c.move(-1)
 }
 
 // Next to select the next suggestion item.
 func (c *CompletionManager) Next() {
-	if c.verticalScroll+int(c.max)-1 == c.selected {
-		c.verticalScroll++
+	c.move(1)
+}
+
+// PreviousGroup selects the first suggestion of the current group,
+// or of the previous group when the first one is already selected.
+func (c *CompletionManager) PreviousGroup() {
+	if c.selected == -1 {
+		c.Previous()
+		return
+	}
+	prev := -1
+	for _, i := range c.groupStarts() {
+		if i < c.selected {
+			prev = i
+		}
 	}
-	c.selected++
+	c.selected = prev
 	c.update()
 }
 
+// NextGroup selects the first suggestion of the next group,
+// or of the first group when the last one is already selected.
+func (c *CompletionManager) NextGroup() {
+	starts := c.groupStarts()
+	if len(starts) == 0 {
+		return
+	}
+	next := starts[0]
+	for _, i := range starts {
+		if i > c.selected {
+			next = i
+			break
+		}
+	}
+	c.selected = next
+	c.update()
+}
+
+// Scroll scrolls the drop down by n rows, down when n is positive, without changing the selection.
+func (c *CompletionManager) Scroll(n int) {
+	c.verticalScroll += n
+	if max := len(c.tmp) - int(c.max); c.verticalScroll > max {
+		c.verticalScroll = max
+	}
+	if c.verticalScroll < 0 {
+		c.verticalScroll = 0
+	}
+}
+
 // Completing returns whether the CompletionManager selects something one.
 func (c *CompletionManager) Completing() bool {
 	return c.selected != -1 && c.selected < len(c.tmp)
 }
 
+// groupStarts returns the indexes of the first selectable item of every group in ascending order.
+func (c *CompletionManager) groupStarts() []int {
+	var starts []int
+	for i := range c.tmp {
+		if c.isHeader(i) {
+			continue
+		}
+		if i == 0 || c.isHeader(i-1) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// move selects the item n rows away, skipping section headers.
+func (c *CompletionManager) move(n int) {
+	c.selected += n
+	for c.selected >= 0 && c.selected < len(c.tmp) && c.isHeader(c.selected) {
+		c.selected += n
+	}
+	c.update()
+}
+
 func (c *CompletionManager) update() {
 	max := int(c.max)
 	if len(c.tmp) < max {
@@ -114,10 +207,58 @@ func (c *CompletionManager) update() {
 
 	if c.selected >= len(c.tmp) {
 		c.Reset()
+		return
 	} else if c.selected < -1 {
 		c.selected = len(c.tmp) - 1
-		c.verticalScroll = len(c.tmp) - max
 	}
+
+	if c.selected == -1 {
+		c.verticalScroll = 0
+		return
+	}
+	top := c.selected
+	if c.isHeader(top - 1) {
+		// Keep the header of the selected group in sight.
+		top--
+	}
+	if top < c.verticalScroll {
+		c.verticalScroll = top
+	}
+	if c.selected >= c.verticalScroll+max {
+		c.verticalScroll = c.selected - max + 1
+	}
+}
+
+// groupSuggestions orders suggests by group and puts a header in front of every group,
+// telling which items are headers in headers. Suggestions without a group come first and have no header.
+// When no suggestion has a group, suggests is returned as is, with nil headers.
+func groupSuggestions(suggests []Suggest) (grouped []Suggest, headers []bool) {
+	var order []string
+	groups := make(map[string][]Suggest)
+	for i := range suggests {
+		g := suggests[i].Group
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], suggests[i])
+	}
+	if len(order) == 0 || (len(order) == 1 && order[0] == "") {
+		return suggests, nil
+	}
+
+	grouped = make([]Suggest, 0, len(suggests)+len(order))
+	headers = make([]bool, len(groups[""]), len(suggests)+len(order))
+	grouped = append(grouped, groups[""]...)
+	for _, g := range order {
+		if g == "" {
+			continue
+		}
+		grouped = append(grouped, Suggest{Text: g, Group: g})
+		headers = append(headers, true)
+		grouped = append(grouped, groups[g]...)
+		headers = append(headers, make([]bool, len(groups[g]))...)
+	}
+	return grouped, headers
 }
 
 func deleteBreakLineCharacters(s string) string {
@@ -175,20 +316,39 @@ func ellipsize(text string, max int) string {
 	return text
 }
 
-func formatSuggestions(suggests []Suggest, max int, maxTextWidth int, maxDescriptionWidth int) (new []Suggest, width int) {
+// formatSuggestions pads the suggestions into the columns of the drop down. The items that headers tells
+// are section headers only keep their text, and may be followed by a nil headers.
+func formatSuggestions(suggests []Suggest, headers []bool, max int, maxTextWidth int, maxDescriptionWidth int) (new []Suggest, width int) {
 	num := len(suggests)
 	new = make([]Suggest, num)
 
+	iconWidth := 0
+	for i := 0; i < num; i++ {
+		if w := runewidth.StringWidth(suggests[i].Icon); w > iconWidth {
+			iconWidth = w
+		}
+	}
+
 	left := make([]string, num)
 	for i := 0; i < num; i++ {
+		if i < len(headers) && headers[i] {
+			left[i] = suggests[i].Text
+			continue
+		}
 		text := suggests[i].Text
 		if suggests[i].CompletionText != "" {
 			text = suggests[i].CompletionText
 		}
 		left[i] = ellipsize(text, maxTextWidth)
+		if iconWidth > 0 {
+			left[i] = runewidth.FillRight(suggests[i].Icon, iconWidth) + " " + left[i]
+		}
 	}
 	right := make([]string, num)
 	for i := 0; i < num; i++ {
+		if i < len(headers) && headers[i] {
+			continue
+		}
 		right[i] = ellipsize(suggests[i].Description, maxDescriptionWidth)
 	}
 
@@ -199,7 +359,7 @@ func formatSuggestions(suggests []Suggest, max int, maxTextWidth int, maxDescrip
 	right, rightWidth := formatTexts(right, max-leftWidth, rightPrefix, rightSuffix)
 
 	for i := 0; i < num; i++ {
-		new[i] = Suggest{Text: left[i], Description: right[i]}
+		new[i] = Suggest{Text: left[i], Description: right[i], Style: suggests[i].Style}
 	}
 	return new, leftWidth + rightWidth
 }
@@ -8,6 +8,7 @@ import (
 func TestFormatShortSuggestion(t *testing.T) {
 	var scenarioTable = []struct {
 		in       []Suggest
+		headers  []bool
 		expected []Suggest
 		max      int
 		exWidth  int
@@ -104,10 +105,39 @@ func TestFormatShortSuggestion(t *testing.T) {
 			max:     500,
 			exWidth: len(" --include-extended-apis       " + " If true, include definitions of new APIs via calls to the API server. [default true]                                                            "),
 		},
+		{
+			in: []Suggest{
+				{Text: "keywords"},
+				{Text: "select", Description: "Query rows"},
+				{Text: "from", Description: "Pick a table"},
+			},
+			headers: []bool{true, false, false},
+			expected: []Suggest{
+				{Text: " keywords ", Description: "              "},
+				{Text: " select   ", Description: " Query rows   "},
+				{Text: " from     ", Description: " Pick a table "},
+			},
+			max:     100,
+			exWidth: len(" keywords " + " Pick a table "),
+		},
+		{
+			in: []Suggest{
+				{Text: "count", Icon: "f", Style: &Style{TextColor: Yellow}},
+				{Text: "limit", Icon: "kw", Style: &Style{CrossedOut: true}},
+				{Text: "users"},
+			},
+			expected: []Suggest{
+				{Text: " f  count ", Style: &Style{TextColor: Yellow}},
+				{Text: " kw limit ", Style: &Style{CrossedOut: true}},
+				{Text: "    users "},
+			},
+			max:     100,
+			exWidth: len(" kw limit "),
+		},
 	}
 
 	for i, s := range scenarioTable {
-		actual, width := formatSuggestions(s.in, s.max, 0, 0)
+		actual, width := formatSuggestions(s.in, s.headers, s.max, 0, 0)
 		if width != s.exWidth {
 			t.Errorf("[scenario %d] Want %d but got %d\n", i, s.exWidth, width)
 		}
@@ -204,3 +234,129 @@ func TestFormatText(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupSuggestions(t *testing.T) {
+	var scenarioTable = []struct {
+		in       []Suggest
+		expected []Suggest
+		headers  []bool
+	}{
+		{
+			in: []Suggest{
+				{Text: "select"},
+				{Text: "from"},
+			},
+			expected: []Suggest{
+				{Text: "select"},
+				{Text: "from"},
+			},
+		},
+		{
+			in: []Suggest{
+				{Text: "select", Group: "keywords"},
+				{Text: "users", Group: "tables"},
+				{Text: "from", Group: "keywords"},
+				{Text: "help"},
+			},
+			expected: []Suggest{
+				{Text: "help"},
+				{Text: "keywords", Group: "keywords"},
+				{Text: "select", Group: "keywords"},
+				{Text: "from", Group: "keywords"},
+				{Text: "tables", Group: "tables"},
+				{Text: "users", Group: "tables"},
+			},
+			headers: []bool{false, true, false, false, true, false},
+		},
+	}
+
+	for i, s := range scenarioTable {
+		actual, headers := groupSuggestions(s.in)
+		if !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("[scenario %d] Want %#v, but got %#v\n", i, s.expected, actual)
+		}
+		if !reflect.DeepEqual(headers, s.headers) {
+			t.Errorf("[scenario %d] Want headers %v, but got %v\n", i, s.headers, headers)
+		}
+	}
+}
+
+func TestCompletionManagerSkipsGroupHeaders(t *testing.T) {
+	c := NewCompletionManager(nil, 3)
+	c.SetResults([]Suggest{
+		{Text: "select", Group: "keywords"},
+		{Text: "from", Group: "keywords"},
+		{Text: "users", Group: "tables"},
+		{Text: "articles", Group: "tables"},
+	})
+
+	var selected []string
+	for i := 0; i < 5; i++ {
+		c.Next()
+		s, _ := c.GetSelectedSuggestion()
+		selected = append(selected, s.Text)
+	}
+	expected := []string{"select", "from", "users", "articles", ""}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Want %#v, but got %#v", expected, selected)
+	}
+
+	c.Previous()
+	if c.selected != 5 || c.verticalScroll != 3 {
+		t.Errorf("Want selected 5 and scroll 3, but got %d and %d", c.selected, c.verticalScroll)
+	}
+	c.Previous()
+	c.Previous()
+	if c.selected != 2 || c.verticalScroll != 2 {
+		t.Errorf("Want selected 2 and scroll 2, but got %d and %d", c.selected, c.verticalScroll)
+	}
+	c.Previous()
+	c.Previous()
+	if c.Completing() {
+		t.Errorf("Should not be completing after moving before the first item, but selected %d", c.selected)
+	}
+}
+
+func TestCompletionManagerJumpsBetweenGroups(t *testing.T) {
+	c := NewCompletionManager(nil, 6)
+	c.SetResults([]Suggest{
+		{Text: "select", Group: "keywords"},
+		{Text: "from", Group: "keywords"},
+		{Text: "users", Group: "tables"},
+		{Text: "articles", Group: "tables"},
+	})
+
+	var selected []string
+	record := func() {
+		s, _ := c.GetSelectedSuggestion()
+		selected = append(selected, s.Text)
+	}
+	c.NextGroup()
+	record()
+	c.NextGroup()
+	record()
+	c.Next()
+	record()
+	c.PreviousGroup()
+	record()
+	c.PreviousGroup()
+	record()
+	c.NextGroup()
+	c.NextGroup()
+	record()
+
+	expected := []string{"select", "users", "articles", "users", "select", "select"}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Want %#v, but got %#v", expected, selected)
+	}
+	if n := len(c.GetSuggestions()); n != 4 {
+		t.Errorf("Want the 4 suggestions without the headers, but got %d", n)
+	}
+
+	c.SetResults(nil)
+	c.Reset()
+	c.NextGroup()
+	if c.Completing() {
+		t.Errorf("Should select nothing without suggestions, but selected %d", c.selected)
+	}
+}
@@ -0,0 +1,110 @@
+package prompt
+
+import "github.com/aschey/go-prompt/internal/debug"
+
+// controlResult is what the Run loop does after running a control function.
+type controlResult struct {
+	// update asks the completer for suggestions.
+	update bool
+}
+
+// control has the Run loop run fn, then draw the prompt again. It doesn't wait for it.
+// Calls made before Run starts, or while the executor runs, are run in order once the loop gets to them.
+// Calls are dropped while the loop is behind by a full buffer, and when Run returns before getting to them.
+// As they only change what's drawn, the next calls draw the prompt again anyway.
+func (p *Prompt) control(fn func(*controlResult)) {
+	select {
+	case p.controlCh <- fn:
+	default:
+		debug.Log("drop control call")
+	}
+}
+
+// applyControls runs the control calls waiting for the Run loop, without drawing the prompt.
+func (p *Prompt) applyControls() {
+	for {
+		select {
+		case fn := <-p.controlCh:
+			fn(&controlResult{})
+		default:
+			return
+		}
+	}
+}
+
+// discardControls drops the control calls and the exit Run didn't get to, so that they don't apply to the next one.
+func (p *Prompt) discardControls() {
+	for {
+		select {
+		case <-p.controlCh:
+		case <-p.exitCh:
+		default:
+			return
+		}
+	}
+}
+
+// SetText replaces the input with text, with the cursor at its end. It's safe to call from any goroutine.
+func (p *Prompt) SetText(text string) {
+	p.control(func(c *controlResult) {
+		p.completion.Reset()
+		p.buf.setDocument(&Document{Text: text, cursorPosition: len([]rune(text))})
+		c.update = true
+	})
+}
+
+// InsertText inserts text at the cursor. It's safe to call from any goroutine.
+func (p *Prompt) InsertText(text string) {
+	p.control(func(c *controlResult) {
+		p.acceptCompletion()
+		p.buf.InsertText(text, false, true)
+		c.update = true
+	})
+}
+
+// MoveCursor moves the cursor n characters to the right, or to the left when n is negative.
+// It's safe to call from any goroutine.
+func (p *Prompt) MoveCursor(n int) {
+	p.control(func(c *controlResult) {
+		if n < 0 {
+			p.buf.CursorLeft(-n)
+		} else {
+			p.buf.CursorRight(n)
+		}
+		c.update = true
+	})
+}
+
+// Complete asks the completer for suggestions for the input, and shows them.
+// It's safe to call from any goroutine.
+func (p *Prompt) Complete() {
+	p.control(func(c *controlResult) {
+		c.update = true
+	})
+}
+
+// SetPrefix changes the prefix. Live prefixes still take precedence over it.
+// It's safe to call from any goroutine.
+func (p *Prompt) SetPrefix(prefix string) {
+	p.control(func(*controlResult) {
+		p.renderer.prefix = prefix
+	})
+}
+
+// Refresh draws the prompt again from scratch, like after something else wrote over it.
+// It's safe to call from any goroutine.
+func (p *Prompt) Refresh() {
+	p.control(func(*controlResult) {
+		p.renderer.invalidate()
+	})
+}
+
+// Exit has Run return code, once it applied the calls made before. Unlike them, it's never dropped
+// while Run is running, though only the first of several exits counts. It's safe to call from any goroutine.
+func (p *Prompt) Exit(code int) {
+	select {
+	case p.exitCh <- code:
+	default:
+		debug.Log("drop exit, as another one is pending")
+	}
+}
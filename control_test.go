@@ -0,0 +1,65 @@
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPromptControl(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("\x1b[1;1R"))
+	var out bytes.Buffer
+	var completed []string
+	var p *Prompt
+	p = New(nil, func(d Document, results chan []Suggest) {
+		completed = append(completed, d.Text)
+		results <- nil
+		if d.Text == "selexct" {
+			// The second exit is dropped when Run returns.
+			p.Exit(3)
+			p.Exit(4)
+		}
+	},
+		OptionParser(NewStreamParser(r)),
+		OptionWriter(NewStreamWriter(&out)),
+		OptionSignals(),
+	)
+
+	// Calls made before Run are applied once it starts.
+	p.SetText("select")
+	p.MoveCursor(-2)
+	p.InsertText("x")
+	p.MoveCursor(1)
+	p.SetPrefix("sql> ")
+	p.Refresh()
+	p.Complete()
+	if code := p.Run(); code != 3 {
+		t.Errorf("Want exit code 3, but got %d", code)
+	}
+	if n := len(p.controlCh); n != 0 {
+		t.Errorf("Should drop the calls left when Run returns, but %d are waiting", n)
+	}
+	for i := 0; i < cap(p.controlCh)+1; i++ {
+		// Calls don't block once the buffer is full.
+		p.Refresh()
+	}
+
+	if text, pos := p.buf.Text(), p.buf.cursorPosition; text != "selexct" || pos != 6 {
+		t.Errorf("Want %q with the cursor at 6, but got %q at %d", "selexct", text, pos)
+	}
+	if !strings.Contains(out.String(), "sql> ") {
+		t.Errorf("Should draw the new prefix, but got %q", out.String())
+	}
+	if len(completed) == 0 || completed[len(completed)-1] != "selexct" {
+		t.Errorf("Should ask the completer for suggestions, but got %#v", completed)
+	}
+
+	// Exit isn't dropped along with the calls that don't fit in the buffer.
+	p.Exit(5)
+	if code := p.Run(); code != 5 {
+		t.Errorf("Want exit code 5, but got %d", code)
+	}
+}
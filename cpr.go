@@ -0,0 +1,154 @@
+package prompt
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aschey/go-prompt/internal/debug"
+)
+
+// cprTimeout is how long the prompt waits for the terminal to report the cursor position at startup.
+const cprTimeout = 200 * time.Millisecond
+
+var cursorPositionReportRegexp = regexp.MustCompile(`\x1b\[(\d+);(\d+)R`)
+
+// ParseCursorPositionReport decodes b when it's a cursor position report, ESC [ row ; col R,
+// which terminals send in response to ConsoleWriter.AskForCPR. The row and the column are counted from 1.
+func ParseCursorPositionReport(b []byte) (row, col int, ok bool) {
+	m := cursorPositionReportRegexp.FindSubmatchIndex(b)
+	if m == nil || m[0] != 0 || m[1] != len(b) {
+		return 0, 0, false
+	}
+	return parseCursorPosition(b, m)
+}
+
+// findCursorPositionReport looks for a cursor position report in b, which may hold keys typed around it.
+// It returns the reported position along with the rest of b.
+func findCursorPositionReport(b []byte) (row, col int, rest []byte, ok bool) {
+	m := cursorPositionReportRegexp.FindSubmatchIndex(b)
+	if m == nil {
+		return 0, 0, b, false
+	}
+	row, col, ok = parseCursorPosition(b, m)
+	rest = append(append([]byte{}, b[:m[0]]...), b[m[1]:]...)
+	return row, col, rest, ok
+}
+
+// withoutCursorPositionReports returns b without the cursor position reports in it, and the position of the last one.
+func withoutCursorPositionReports(b []byte) (rest []byte, row, col int, ok bool) {
+	rest = b
+	for {
+		r, c, in, found := findCursorPositionReport(rest)
+		if !found {
+			return rest, row, col, ok
+		}
+		rest, row, col, ok = in, r, c, true
+	}
+}
+
+func parseCursorPosition(b []byte, m []int) (row, col int, ok bool) {
+	row, err := strconv.Atoi(string(b[m[2]:m[3]]))
+	if err != nil {
+		return 0, 0, false
+	}
+	col, err = strconv.Atoi(string(b[m[4]:m[5]]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return row, col, true
+}
+
+// locateCursor asks the terminal where the cursor is before the prompt is first drawn,
+// and returns the keys typed in the meantime. Terminals that may not answer aren't asked,
+// so that the prompt doesn't wait for them.
+func (p *Prompt) locateCursor() []byte {
+	if p.renderer.fullScreen || !answersCPR() {
+		return nil
+	}
+	p.drawHidden(p.renderer.askForCursorPosition)
+
+	var b []byte
+	for deadline := time.Now().Add(cprTimeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if in, err := p.in.Read(); err == nil && !(len(in) == 1 && in[0] == 0) {
+			b = append(b, in...)
+			atomic.AddUint32(&p.reads, 1)
+		}
+		if row, col, rest, ok := findCursorPositionReport(b); ok {
+			p.drawHidden(func() { p.renderer.startAt(row, col) })
+			return rest
+		}
+	}
+	return b
+}
+
+// answersCPR tells whether the terminal is known to answer cursor position requests, from TERM.
+func answersCPR() bool {
+	term := os.Getenv("TERM")
+	return term != "" && term != "dumb"
+}
+
+// takeCursorPositionReport takes in the cursor position report the terminal may have sent along with
+// the keys in b, and returns the keys.
+func (p *Prompt) takeCursorPositionReport(b []byte) []byte {
+	rest, row, col, ok := withoutCursorPositionReports(b)
+	if ok {
+		p.renderer.cursorReported(row, col)
+	}
+	return rest
+}
+
+// CursorPosition returns the row and the column of the cursor on the screen, counted from 0.
+// It returns false while they're unknown, from when a line is accepted until the terminal reports
+// where the next prompt is drawn. It's meant for the executor and the callbacks of the prompt,
+// which run on the goroutine of Run.
+func (p *Prompt) CursorPosition() (row, col int, ok bool) {
+	r := p.renderer
+	if !r.originKnown {
+		return 0, 0, false
+	}
+	return r.originRow + r.cursorY, r.cursorX, true
+}
+
+// askForCursorPosition asks the terminal where the cursor is. The answer is handed to cursorReported.
+func (r *Render) askForCursorPosition() {
+	r.out.AskForCPR()
+	debug.AssertNoError(r.flush())
+	r.cprPending = true
+	r.cprY = r.cursorY
+}
+
+// setReading sets whether the input is read in raw mode, asking where the prompt drawn meanwhile is once it is.
+// The terminal is only asked while the input is read, so that its answer is neither echoed nor left to
+// whatever reads the terminal next.
+func (r *Render) setReading(reading bool) {
+	r.reading = reading
+	if reading && !r.fullScreen && r.previous != nil && !r.originKnown && !r.cprPending {
+		r.askForCursorPosition()
+	}
+}
+
+// cursorReported takes in the position of the cursor the terminal reported, counted from 1.
+func (r *Render) cursorReported(row, col int) {
+	if !r.cprPending {
+		return
+	}
+	r.cprPending = false
+	r.originRow, r.originKnown = row-1-r.cprY, true
+}
+
+// startAt takes in the position of the cursor before the prompt is first drawn, counted from 1.
+// When a program left a partial line, the prompt starts on the next one.
+func (r *Render) startAt(row, col int) {
+	r.cprPending = false
+	r.originRow, r.originKnown = row-1, true
+	if col > 1 {
+		r.out.WriteRaw([]byte{'\r', '\n'})
+		debug.AssertNoError(r.flush())
+		if r.originRow < int(r.row)-1 {
+			r.originRow++
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseCursorPositionReport(t *testing.T) {
+	scenarioTable := []struct {
+		in  string
+		row int
+		col int
+		ok  bool
+	}{
+		{in: "\x1b[12;40R", row: 12, col: 40, ok: true},
+		{in: "\x1b[1;1R", row: 1, col: 1, ok: true},
+		{in: "a\x1b[1;1R"},
+		{in: "\x1b[1R"},
+		{in: "\x1b[A"},
+	}
+
+	for _, s := range scenarioTable {
+		row, col, ok := ParseCursorPositionReport([]byte(s.in))
+		if row != s.row || col != s.col || ok != s.ok {
+			t.Errorf("%q: should be %d, %d, %v, but got %d, %d, %v", s.in, s.row, s.col, s.ok, row, col, ok)
+		}
+	}
+}
+
+// scriptedParser is a ConsoleParser returning reads one after the other.
+type scriptedParser struct {
+	reads [][]byte
+}
+
+func (p *scriptedParser) Setup() error         { return nil }
+func (p *scriptedParser) TearDown() error      { return nil }
+func (p *scriptedParser) GetWinSize() *WinSize { return &WinSize{Row: 20, Col: 40} }
+func (p *scriptedParser) Read() ([]byte, error) {
+	if len(p.reads) == 0 {
+		return nil, errors.New("EAGAIN")
+	}
+	b := p.reads[0]
+	p.reads = p.reads[1:]
+	return b, nil
+}
+
+func TestPromptLocateCursor(t *testing.T) {
+	defer restoreEnv("TERM")()
+	os.Setenv("TERM", "xterm")
+	w := &bufferWriter{}
+	p := &Prompt{
+		in:  &scriptedParser{reads: [][]byte{[]byte("ls"), []byte(" -l\x1b[6;8R")}},
+		buf: NewBuffer(),
+		renderer: &Render{
+			out:                w,
+			prefix:             "> ",
+			livePrefixCallback: func() (string, bool) { return "", false },
+			theme:              DefaultTheme(),
+			row:                20,
+			col:                40,
+		},
+		completion: NewCompletionManager(nil, 6),
+	}
+
+	if typeahead := string(p.locateCursor()); typeahead != "ls -l" {
+		t.Errorf("Should return the keys typed meanwhile, but got %q", typeahead)
+	}
+	if out := string(w.buffer); out != "\x1b[6n\r\n" {
+		t.Errorf("Should ask for the position and break the partial line, but got %q", out)
+	}
+	p.renderer.Render(p.buf, p.completion)
+	if row, col, ok := p.CursorPosition(); row != 6 || col != 2 || !ok {
+		t.Errorf("Should be on row 6 after the prefix, but got %d, %d, %v", row, col, ok)
+	}
+
+	p.renderer.BreakLine(p.buf)
+	if _, _, ok := p.CursorPosition(); ok {
+		t.Error("Should not know where the next prompt is drawn")
+	}
+	w.buffer = nil
+	p.renderer.Render(p.buf, p.completion)
+	if out := string(w.buffer); strings.Contains(out, "\x1b[6n") {
+		t.Errorf("Should not ask for the position while the input isn't read, but got %q", out)
+	}
+	w.buffer = nil
+	p.renderer.setReading(true)
+	if out := string(w.buffer); out != "\x1b[6n" {
+		t.Errorf("Should ask for the position once the input is read, but got %q", out)
+	}
+	if keys := string(p.takeCursorPositionReport([]byte("ab\x1b[9;3Rc"))); keys != "abc" {
+		t.Errorf("Should return the keys around the report, but got %q", keys)
+	}
+	if row, col, ok := p.CursorPosition(); row != 8 || col != 2 || !ok {
+		t.Errorf("Should be on row 8 after the prefix, but got %d, %d, %v", row, col, ok)
+	}
+
+	os.Setenv("TERM", "dumb")
+	w.buffer = nil
+	if p.locateCursor(); len(w.buffer) != 0 {
+		t.Errorf("Should not ask a dumb terminal, but got %q", w.buffer)
+	}
+}
@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+const (
+	documentationMinWidth = 20
+	documentationMaxWidth = 60
+)
+
+// DocumentationLoader returns the documentation shown beside the drop down for a suggestion,
+// like a function signature followed by examples. It may span several lines.
+// It is called lazily, only once the suggestion gets selected.
+type DocumentationLoader func(Suggest) string
+
+// Documentation returns the documentation of the selected suggestion.
+// It is loaded on first use and cached until the selection or the suggestions change.
+func (c *CompletionManager) Documentation() (string, bool) {
+	if c.documentationLoader == nil {
+		return "", false
+	}
+	s, ok := c.GetSelectedSuggestion()
+	if !ok {
+		return "", false
+	}
+	if !c.documentationLoaded || c.documentationFor != c.selected {
+		c.documentation = c.documentationLoader(s)
+		c.documentationFor = c.selected
+		c.documentationLoaded = true
+		c.documentationScroll = 0
+	}
+	return c.documentation, c.documentation != ""
+}
+
+// ScrollDocumentationUp scrolls the documentation of the selected suggestion up by one line.
+func (c *CompletionManager) ScrollDocumentationUp() {
+	if c.documentationScroll > 0 {
+		c.documentationScroll--
+	}
+}
+
+// ScrollDocumentationDown scrolls the documentation of the selected suggestion down by one line.
+// The renderer clamps the position once it knows how many lines fit.
+func (c *CompletionManager) ScrollDocumentationDown() {
+	c.documentationScroll++
+}
+
+// documentationLines returns at most height lines of the documentation wrapped to width,
+// starting from the current scroll position.
+func (c *CompletionManager) documentationLines(doc string, width, height int) []string {
+	lines := wrapText(doc, width)
+	if max := len(lines) - height; c.documentationScroll > max {
+		c.documentationScroll = max
+	}
+	if c.documentationScroll < 0 {
+		c.documentationScroll = 0
+	}
+	lines = lines[c.documentationScroll:]
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+// wrapText splits s into lines no wider than width, breaking at spaces when possible.
+// Indentation at the start of a line is kept, which matters for code examples.
+func wrapText(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	s = strings.Replace(s, "\r", "", -1)
+	s = strings.Replace(s, "\t", "    ", -1)
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		runes := []rune(paragraph)
+		if len(runes) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		for len(runes) > 0 {
+			cut := len(runes)
+			lastSpace := -1
+			w := 0
+			for i, r := range runes {
+				rw := runewidth.RuneWidth(r)
+				if w+rw > width {
+					cut = i
+					break
+				}
+				w += rw
+				if r == ' ' {
+					lastSpace = i
+				}
+			}
+			if cut < len(runes) && lastSpace > 0 {
+				cut = lastSpace
+			}
+			if cut == 0 {
+				// A single rune wider than the whole line.
+				cut = 1
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+			runes = runes[cut:]
+			for len(runes) > 0 && runes[0] == ' ' {
+				runes = runes[1:]
+			}
+		}
+	}
+	return lines
+}
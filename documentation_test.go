@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	scenarioTable := []struct {
+		in       string
+		width    int
+		expected []string
+	}{
+		{
+			in:       "count(expr)",
+			width:    20,
+			expected: []string{"count(expr)"},
+		},
+		{
+			in:       "Returns the number of rows matching the query.",
+			width:    16,
+			expected: []string{"Returns the", "number of rows", "matching the", "query."},
+		},
+		{
+			in:       "Example:\n  SELECT count(*)\n\nDone",
+			width:    20,
+			expected: []string{"Example:", "  SELECT count(*)", "", "Done"},
+		},
+		{
+			in:       "abcdefghij",
+			width:    4,
+			expected: []string{"abcd", "efgh", "ij"},
+		},
+		{
+			in:       "日本語のテキスト",
+			width:    5,
+			expected: []string{"日本", "語の", "テキ", "スト"},
+		},
+	}
+
+	for _, s := range scenarioTable {
+		if actual := wrapText(s.in, s.width); !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("Should be %#v, but got %#v", s.expected, actual)
+		}
+	}
+}
+
+func TestCompletionManagerDocumentation(t *testing.T) {
+	loaded := 0
+	c := NewCompletionManager(nil, 6)
+	c.documentationLoader = func(s Suggest) string {
+		loaded++
+		return s.Text + "\nline 2\nline 3"
+	}
+	c.SetResults([]Suggest{{Text: "count"}, {Text: "sum"}})
+
+	if _, ok := c.Documentation(); ok {
+		t.Errorf("Should not have documentation without a selection")
+	}
+
+	c.Next()
+	c.Documentation()
+	doc, ok := c.Documentation()
+	if !ok || doc != "count\nline 2\nline 3" || loaded != 1 {
+		t.Errorf("Should load the documentation of count once, but got %q after %d loads", doc, loaded)
+	}
+
+	c.ScrollDocumentationDown()
+	c.ScrollDocumentationDown()
+	c.ScrollDocumentationDown()
+	lines := c.documentationLines(doc, 10, 2)
+	if expected := []string{"line 2", "line 3"}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, lines)
+	}
+
+	c.Next()
+	if doc, _ := c.Documentation(); doc != "sum\nline 2\nline 3" || c.documentationScroll != 0 {
+		t.Errorf("Should reload the documentation and reset the scroll position, but got %q at %d", doc, c.documentationScroll)
+	}
+}
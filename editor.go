@@ -0,0 +1,101 @@
+package prompt
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/aschey/go-prompt/internal/debug"
+)
+
+// editText edits text in the editor of the user, $VISUAL or $EDITOR, and returns what it was saved as.
+func editText(text string) (string, error) {
+	f, err := ioutil.TempFile("", "prompt-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(text + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return "", errors.New("no editor")
+	}
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// editInEditor hands the terminal to the editor to edit the input, then draws the prompt again with what
+// the input was saved as. The input is left as it was when the editor fails.
+func (p *Prompt) editInEditor() {
+	p.setShown(false, func() { p.renderer.suspend(p.buf) })
+	debug.AssertNoError(p.in.TearDown())
+	p.renderer.setReading(false)
+
+	text, err := p.editor(p.buf.Text())
+	if err != nil {
+		p.drawHidden(func() { p.renderer.renderError(err) })
+	} else {
+		before := *p.buf.Document()
+		p.completion.Reset()
+		p.buf.setDocument(&Document{Text: text, cursorPosition: len([]rune(text))})
+		p.recordUndo(p.buf, before)
+	}
+
+	debug.AssertNoError(p.in.Setup())
+	p.renderer.UpdateWinSize(p.in.GetWinSize())
+	p.setShown(true, p.renderer.resume)
+	p.renderer.setReading(true)
+}
+
+// undo restores the input as it was before its last change.
+func (p *Prompt) undo() {
+	n := len(p.undoStack)
+	if n == 0 {
+		return
+	}
+	d := p.undoStack[n-1]
+	p.undoStack = p.undoStack[:n-1]
+	p.completion.Reset()
+	p.buf.setDocument(&d)
+}
+
+// recordUndo saves the input as it was before a change made to buf. The changes are forgotten when
+// another input replaced buf, like once the input is accepted.
+func (p *Prompt) recordUndo(buf *Buffer, before Document) {
+	if p.buf != buf {
+		p.undoStack = nil
+		return
+	}
+	if p.buf.Text() != before.Text {
+		p.undoStack = append(p.undoStack, before)
+	}
+}
@@ -1,7 +1,5 @@
 package prompt
 
-import "github.com/aschey/go-prompt/internal/debug"
-
 /*
 
 ========
@@ -108,13 +106,4 @@ var emacsKeyBindings = []KeyBind{
 			buf.DeleteBeforeCursor(len([]rune(buf.Document().GetWordBeforeCursorWithSpace())))
 		},
 	},
-	// Clear the Screen, similar to the clear command
-	{
-		Key: ControlL,
-		Fn: func(buf *Buffer) {
-			consoleWriter.EraseScreen()
-			consoleWriter.CursorGoTo(0, 0)
-			debug.AssertNoError(consoleWriter.Flush())
-		},
-	},
 }
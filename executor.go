@@ -0,0 +1,134 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// ExecutorContext is an Executor given a context, which is cancelled when the user interrupts it with Ctrl+c.
+// The error it returns is shown by the error handler, and sets the exit status of the input:
+// the code of an ExitError or of an error with an ExitCode method like *exec.ExitError, and 1 otherwise.
+type ExecutorContext func(ctx context.Context, in string, chosen *Suggest, suggestions []Suggest) error
+
+// ErrorHandler is called with the error returned by an ExecutorContext.
+type ErrorHandler func(err error)
+
+// ExitError is returned by an ExecutorContext to stop Run, which returns Code.
+// Err, when set, is shown by the error handler first.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Unwrap returns Err.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitStatus returns the exit status matching the error returned by an executor.
+func exitStatus(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) && coder.ExitCode() > 0 {
+		return coder.ExitCode()
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// execute runs the executor on the accepted input, and returns whether Run exits, and with which code.
+// An ExecutorContext is run with interrupts handled, so that Ctrl+c cancels it instead of killing the process.
+func (p *Prompt) execute(in string, chosen *Suggest, suggestions []Suggest) (exit bool, code int) {
+	if p.executorContext == nil {
+		p.executor(in, chosen, suggestions)
+		return false, 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The terminal isn't in raw mode, so Ctrl+c sends SIGINT to the process.
+	sigCh := make(chan os.Signal, 1)
+	if p.subscribes(os.Interrupt) {
+		signal.Notify(sigCh, os.Interrupt)
+	}
+	defer signal.Stop(sigCh)
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-sigCh:
+				action, c := p.interruptAction(Interrupt{
+					Input:     Document{Text: in, cursorPosition: len([]rune(in))},
+					Signal:    true,
+					Executing: true,
+				})
+				switch action {
+				case InterruptCancelLine, InterruptCancelExecutor:
+					cancel()
+				case InterruptExit:
+					exit, code = true, c
+					cancel()
+				}
+			}
+		}
+	}()
+
+	err := p.executorContext(ctx, in, chosen, suggestions)
+	close(stop)
+	<-stopped
+
+	p.renderer.status = exitStatus(err)
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		exit, code = true, exitErr.Code
+		err = exitErr.Err
+	}
+	if err != nil {
+		p.showError(err)
+	}
+	return exit, code
+}
+
+// showError shows an error returned by the executor with the error handler,
+// or in the error style by default.
+func (p *Prompt) showError(err error) {
+	if p.errorHandler != nil {
+		p.errorHandler(err)
+		return
+	}
+	p.drawHidden(func() { p.renderer.renderError(err) })
+}
+
+// subscribes tells whether the prompt subscribes to sig.
+func (p *Prompt) subscribes(sig os.Signal) bool {
+	signals := p.signals
+	if signals == nil {
+		signals = defaultSignals
+	}
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPromptExecuteContext(t *testing.T) {
+	interrupt := func(ctx context.Context) bool {
+		syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	var cancelled bool
+	p := &Prompt{renderer: &Render{out: &bufferWriter{}, theme: DefaultTheme()}}
+	OptionExecutorContext(func(ctx context.Context, in string, _ *Suggest, _ []Suggest) error {
+		cancelled = interrupt(ctx)
+		return nil
+	})(p)
+	if exit, _ := p.execute("sleep 10", nil, nil); exit || !cancelled {
+		t.Errorf("Should cancel the executor and keep running, but got exit %t and cancelled %t", exit, cancelled)
+	}
+
+	var got Interrupt
+	OptionInterruptHandler(func(i Interrupt) (InterruptAction, int) {
+		got = i
+		return InterruptExit, 130
+	})(p)
+	cancelled = false
+	if exit, code := p.execute("sleep 10", nil, nil); !exit || code != 130 || !cancelled {
+		t.Errorf("Should cancel the executor and exit with code 130, but got exit %t with code %d and cancelled %t", exit, code, cancelled)
+	}
+	if !got.Signal || !got.Executing || got.Input.Text != "sleep 10" {
+		t.Errorf("Should pass the executed input to the handler, but got %#v", got)
+	}
+}
+
+func TestPromptExecuteErrors(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	scenarioTable := []struct {
+		err    error
+		status int
+		exit   bool
+		code   int
+		output string
+	}{
+		{err: nil, status: 0, output: ""},
+		{err: errors.New("no such table"), status: 1, output: "\x1b[0;91;49mno such table\x1b[0;39;49m\n"},
+		{err: exitErr, status: 3, output: "\x1b[0;91;49mexit status 3\x1b[0;39;49m\n"},
+		{err: &ExitError{Code: 2}, status: 2, exit: true, code: 2, output: ""},
+		{
+			err:    fmt.Errorf("quit: %w", &ExitError{Code: 4, Err: errors.New("bye")}),
+			status: 4,
+			exit:   true,
+			code:   4,
+			output: "\x1b[0;91;49mbye\x1b[0;39;49m\n",
+		},
+	}
+
+	for i, s := range scenarioTable {
+		w := &bufferWriter{}
+		p := &Prompt{renderer: &Render{out: w, theme: DefaultTheme()}}
+		OptionExecutorContext(func(context.Context, string, *Suggest, []Suggest) error {
+			return s.err
+		})(p)
+		exit, code := p.execute("select", nil, nil)
+		if exit != s.exit || code != s.code || p.renderer.status != s.status {
+			t.Errorf("[scenario %d] Want exit %t with code %d and status %d, but got %t with %d and %d",
+				i, s.exit, s.code, s.status, exit, code, p.renderer.status)
+		}
+		if out := string(w.buffer); out != s.output {
+			t.Errorf("[scenario %d] Want %q, but got %q", i, s.output, out)
+		}
+	}
+
+	var shown error
+	w := &bufferWriter{}
+	p := &Prompt{renderer: &Render{out: w, prefix: "> ", theme: DefaultTheme(), row: 20, col: 40}}
+	OptionErrorHandler(func(err error) { shown = err })(p)
+	OptionLivePrefixStatus(func(status int) (string, bool) { return fmt.Sprintf("[%d] > ", status), status != 0 })(p)
+	OptionExecutorContext(func(context.Context, string, *Suggest, []Suggest) error {
+		return errors.New("failed")
+	})(p)
+	p.execute("select", nil, nil)
+	if shown == nil || shown.Error() != "failed" {
+		t.Errorf("Should pass the error to the error handler, but got %v", shown)
+	}
+	if prefix := p.renderer.getCurrentPrefix(); !strings.HasPrefix(prefix, "[1]") {
+		t.Errorf("Should give the status to the live prefix, but got %q", prefix)
+	}
+	if segments := p.renderer.plainPrefix("> "); segments[0].Style != p.renderer.theme.Error {
+		t.Errorf("Should draw the prefix in the error style, but got %#v", segments)
+	}
+
+	p.buf, p.history, p.completion = NewBuffer(), NewHistory(), NewCompletionManager(nil, 6)
+	w.buffer = nil
+	p.feed([]byte{'\r'})
+	if out := string(w.buffer); !strings.Contains(out, "[1] > ") {
+		t.Errorf("Should leave the accepted line with the status it was typed with, but got %q", out)
+	}
+	if p.renderer.status != 0 {
+		t.Errorf("Should reset the status when the next input is accepted, but got %d", p.renderer.status)
+	}
+}
+
+func TestPromptRunExits(t *testing.T) {
+	scenarioTable := []struct {
+		option Option
+		code   int
+	}{
+		{
+			option: OptionExecutorContext(func(context.Context, string, *Suggest, []Suggest) error {
+				return &ExitError{Code: 3}
+			}),
+			code: 3,
+		},
+		{
+			option: OptionSetExitCheckerOnInput(func(in string, breakline bool) bool { return breakline }),
+			code:   0,
+		},
+	}
+
+	for i, s := range scenarioTable {
+		r, w := io.Pipe()
+		go func() {
+			for _, s := range []string{"\x1b[1;1R", "quit", "\r"} {
+				w.Write([]byte(s))
+			}
+		}()
+		p := New(func(string, *Suggest, []Suggest) {}, func(d Document, results chan []Suggest) { results <- nil },
+			OptionParser(NewStreamParser(r)),
+			OptionWriter(NewStreamWriter(ioutil.Discard)),
+			OptionSignals(),
+			s.option,
+		)
+		done := make(chan int)
+		go func() { done <- p.Run() }()
+		select {
+		case code := <-done:
+			if code != s.code {
+				t.Errorf("[scenario %d] Want exit code %d, but got %d", i, s.code, code)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("[scenario %d] Run should return once the input is executed", i)
+		}
+		w.Close()
+	}
+}
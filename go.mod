@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/mattn/go-colorable v0.1.12
+	github.com/mattn/go-isatty v0.0.14
 	github.com/mattn/go-runewidth v0.0.13
 	github.com/mattn/go-tty v0.0.3
 	github.com/pkg/term v1.2.0-beta.2
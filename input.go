@@ -27,6 +27,12 @@ func GetKey(b []byte) Key {
 			return k.Key
 		}
 	}
+	if _, ok := ParseMouseEvents(b); ok {
+		return Vt100MouseEvent
+	}
+	if _, _, ok := ParseCursorPositionReport(b); ok {
+		return CPRResponse
+	}
 	return NotDefined
 }
 
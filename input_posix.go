@@ -14,8 +14,9 @@ const maxReadBytes = 1024
 
 // PosixParser is a ConsoleParser implementation for POSIX environment.
 type PosixParser struct {
-	fd          int
-	origTermios syscall.Termios
+	fd int
+	// state is the mode of the terminal before the first Setup, which TearDown restores.
+	state *term.State
 }
 
 // Setup should be called before starting input
@@ -24,9 +25,13 @@ func (t *PosixParser) Setup() error {
 	if err := syscall.SetNonblock(t.fd, true); err != nil {
 		return err
 	}
-	if err := term.SetRaw(t.fd); err != nil {
+	state, err := term.SetRaw(t.fd)
+	if err != nil {
 		return err
 	}
+	if t.state == nil {
+		t.state = state
+	}
 	return nil
 }
 
@@ -35,7 +40,10 @@ func (t *PosixParser) TearDown() error {
 	if err := syscall.SetNonblock(t.fd, false); err != nil {
 		return err
 	}
-	if err := term.Restore(); err != nil {
+	if t.state == nil {
+		return nil
+	}
+	if err := term.Restore(t.fd, t.state); err != nil {
 		return err
 	}
 	return nil
@@ -68,12 +76,19 @@ func (t *PosixParser) GetWinSize() *WinSize {
 	}
 }
 
+// hasTerminal tells whether the parser reads a terminal. It reads the standard input when /dev/tty can't be
+// opened, which may be a pipe or a file.
+func (t *PosixParser) hasTerminal() bool {
+	return t.fd != syscall.Stdin || stdinIsTerminal()
+}
+
 var _ ConsoleParser = &PosixParser{}
 
 // NewStandardInputParser returns ConsoleParser object to read from stdin.
 func NewStandardInputParser() *PosixParser {
 	in, err := syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
-	if os.IsNotExist(err) {
+	// ENXIO means there is no controlling terminal, like when run from cron with a script piped in.
+	if os.IsNotExist(err) || err == syscall.ENXIO {
 		in = syscall.Stdin
 	} else if err != nil {
 		panic(err)
@@ -81,3 +81,17 @@ func (p *WindowsParser) GetWinSize() *WinSize {
 func NewStandardInputParser() *WindowsParser {
 	return &WindowsParser{}
 }
+
+// hasTerminal tells whether the parser can read a console, which it opens even when the standard input
+// is redirected.
+func (p *WindowsParser) hasTerminal() bool {
+	if stdinIsTerminal() {
+		return true
+	}
+	t, err := tty.Open()
+	if err != nil {
+		return false
+	}
+	t.Close()
+	return true
+}
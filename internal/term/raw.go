@@ -6,15 +6,15 @@ import (
 	"syscall"
 
 	"github.com/pkg/term/termios"
-	"golang.org/x/sys/unix"
 )
 
-// SetRaw put terminal into a raw mode
-func SetRaw(fd int) error {
-	n, err := getOriginalTermios(fd)
+// SetRaw put terminal into a raw mode, and returns the mode it was in.
+func SetRaw(fd int) (*State, error) {
+	s, err := GetState(fd)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	n := s.termios
 
 	n.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
 		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
@@ -25,5 +25,8 @@ func SetRaw(fd int) error {
 	n.Cc[syscall.VMIN] = 1
 	n.Cc[syscall.VTIME] = 0
 
-	return termios.Tcsetattr(uintptr(fd), termios.TCSANOW, (*unix.Termios)(n))
+	if err := termios.Tcsetattr(uintptr(fd), termios.TCSANOW, &n); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
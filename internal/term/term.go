@@ -3,32 +3,26 @@
 package term
 
 import (
-	"sync"
-
 	"github.com/pkg/term/termios"
 	"golang.org/x/sys/unix"
 )
 
-var (
-	saveTermios     *unix.Termios
-	saveTermiosFD   int
-	saveTermiosOnce sync.Once
-)
-
-func getOriginalTermios(fd int) (*unix.Termios, error) {
-	var err error
-	saveTermiosOnce.Do(func() {
-		saveTermiosFD = fd
-		saveTermios, err = termios.Tcgetattr(uintptr(fd))
-	})
-	return saveTermios, err
+// State is the mode of a terminal, as saved before changing it.
+type State struct {
+	termios unix.Termios
 }
 
-// Restore terminal's mode.
-func Restore() error {
-	o, err := getOriginalTermios(saveTermiosFD)
+// GetState returns the mode of the terminal fd.
+func GetState(fd int) (*State, error) {
+	t, err := termios.Tcgetattr(uintptr(fd))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return termios.Tcsetattr(uintptr(saveTermiosFD), termios.TCSANOW, o)
+	return &State{termios: *t}, nil
+}
+
+// Restore puts the terminal fd back into the mode s.
+func Restore(fd int, s *State) error {
+	t := s.termios
+	return termios.Tcsetattr(uintptr(fd), termios.TCSANOW, &t)
 }
@@ -0,0 +1,162 @@
+package prompt
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultKeySequenceTimeout is how long the prompt waits for the next key of a sequence.
+const DefaultKeySequenceTimeout = time.Second
+
+// KeySequenceBind represents which sequence of keys, such as Ctrl+x Ctrl+e, should do what operation.
+type KeySequenceBind struct {
+	Keys []Key
+	Fn   KeyBindFunc
+}
+
+// keymap is a trie of key sequences. Each node may run a function, and be the prefix of longer sequences.
+type keymap struct {
+	fn       func(*Prompt)
+	children map[Key]*keymap
+}
+
+func newKeymap() *keymap {
+	return &keymap{children: map[Key]*keymap{}}
+}
+
+// bind binds keys to fn, replacing what they were bound to.
+func (m *keymap) bind(keys []Key, fn func(*Prompt)) {
+	for _, k := range keys {
+		child, ok := m.children[k]
+		if !ok {
+			child = newKeymap()
+			m.children[k] = child
+		}
+		m = child
+	}
+	m.fn = fn
+}
+
+var emacsKeySequences = []struct {
+	keys []Key
+	fn   func(*Prompt)
+	// editor tells whether the sequence needs an editor.
+	editor bool
+}{
+	// Undo the last change of the input
+	{keys: []Key{ControlX, ControlU}, fn: (*Prompt).undo},
+	// Edit the input in the editor of the user
+	{keys: []Key{ControlX, ControlE}, fn: func(p *Prompt) { p.editRequested = true }, editor: true},
+}
+
+// buildKeymap builds the trie of the key sequences bound in the key bind mode, then of the custom ones.
+// The sequences of the key bind mode are left out when a custom key bind takes their first key.
+func (p *Prompt) buildKeymap() *keymap {
+	m := newKeymap()
+	if p.keyBindMode == EmacsKeyBind {
+		for _, s := range emacsKeySequences {
+			if (s.editor && p.editor == nil) || p.boundToKey(s.keys[0]) {
+				continue
+			}
+			m.bind(s.keys, s.fn)
+		}
+	}
+	for i := range p.keySequenceBindings {
+		fn := p.keySequenceBindings[i].Fn
+		m.bind(p.keySequenceBindings[i].Keys, func(p *Prompt) {
+			buf, before := p.buf, *p.buf.Document()
+			fn(p.buf)
+			p.recordUndo(buf, before)
+		})
+	}
+	return m
+}
+
+// boundToKey tells whether a custom key bind takes key.
+func (p *Prompt) boundToKey(key Key) bool {
+	for i := range p.keyBindings {
+		if p.keyBindings[i].Key == key {
+			return true
+		}
+	}
+	for i := range p.ASCIICodeBindings {
+		if GetKey(p.ASCIICodeBindings[i].ASCIICode) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKeySequence takes in the input b, and returns the inputs to handle as single keys:
+// none while b leaves a sequence pending or completes one, which then runs, or else the keys
+// held for a pending sequence followed by b.
+func (p *Prompt) matchKeySequence(b []byte) [][]byte {
+	key := GetKey(b)
+	if key == Vt100MouseEvent {
+		// Mouse events come from the terminal rather than from the keyboard.
+		return [][]byte{b}
+	}
+	node := p.keymap
+	if p.pendingKeymap != nil {
+		node = p.pendingKeymap
+	}
+	child, ok := node.children[key]
+	if !ok {
+		return append(p.resetKeySequence(), b)
+	}
+	if len(child.children) == 0 {
+		p.resetKeySequence()
+		child.fn(p)
+		return nil
+	}
+	p.pendingKeymap = child
+	p.pendingKeys = append(p.pendingKeys, b)
+	p.pendingDeadline = time.Now().Add(p.keySequenceTimeout)
+	p.renderer.pendingKeys = keySequenceText(p.pendingKeys)
+	return nil
+}
+
+// keySequenceExpired tells whether nothing followed the pending sequence in time.
+func (p *Prompt) keySequenceExpired() bool {
+	return p.pendingKeymap != nil && !time.Now().Before(p.pendingDeadline)
+}
+
+// expireKeySequence ends the pending sequence. It runs what its keys are bound to, if anything,
+// or returns them to handle as single keys.
+func (p *Prompt) expireKeySequence() [][]byte {
+	if fn := p.pendingKeymap.fn; fn != nil {
+		p.resetKeySequence()
+		fn(p)
+		return nil
+	}
+	return p.resetKeySequence()
+}
+
+// resetKeySequence forgets the pending sequence, and returns its keys.
+func (p *Prompt) resetKeySequence() [][]byte {
+	keys := p.pendingKeys
+	p.pendingKeymap = nil
+	p.pendingKeys = nil
+	p.renderer.pendingKeys = ""
+	return keys
+}
+
+// keySequenceText returns the pending keys the way emacs shows them, like "C-x-".
+func keySequenceText(keys [][]byte) string {
+	var b strings.Builder
+	for _, k := range keys {
+		key := GetKey(k)
+		switch {
+		case key >= ControlA && key <= ControlZ:
+			b.WriteString("C-" + string(rune('a'+key-ControlA)))
+		case key == Escape:
+			b.WriteString("ESC")
+		case key == NotDefined:
+			b.WriteString(string(k))
+		default:
+			b.WriteString(key.String())
+		}
+		b.WriteString("-")
+	}
+	return b.String()
+}
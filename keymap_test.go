@@ -0,0 +1,193 @@
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchKeySequence(t *testing.T) {
+	var ran string
+	p := &Prompt{renderer: &Render{}, buf: NewBuffer()}
+	p.keymap = newKeymap()
+	p.keymap.bind([]Key{ControlX, ControlE}, func(*Prompt) { ran += "C-x C-e," })
+	p.keymap.bind([]Key{ControlX, ControlX}, func(*Prompt) { ran += "C-x C-x," })
+	p.keymap.bind([]Key{ControlX, Escape, ControlA}, func(*Prompt) { ran += "C-x ESC C-a," })
+
+	scenarioTable := []struct {
+		input   [][]byte
+		handled [][]byte
+		pending string
+		ran     string
+	}{
+		{
+			input:   [][]byte{[]byte("a")},
+			handled: [][]byte{[]byte("a")},
+		},
+		{
+			input:   [][]byte{{0x18}},
+			pending: "C-x-",
+		},
+		{
+			input: [][]byte{{0x18}, {0x5}},
+			ran:   "C-x C-e,",
+		},
+		{
+			input:   [][]byte{{0x18}, {0x1b}},
+			pending: "C-x-ESC-",
+		},
+		{
+			input: [][]byte{{0x18}, {0x1b}, {0x1}},
+			ran:   "C-x ESC C-a,",
+		},
+		{
+			input:   [][]byte{{0x18}, []byte("q")},
+			handled: [][]byte{{0x18}, []byte("q")},
+		},
+		{
+			// Mouse events aren't keys.
+			input:   [][]byte{{0x18}, []byte("\x1b[<0;3;2M")},
+			handled: [][]byte{[]byte("\x1b[<0;3;2M")},
+			pending: "C-x-",
+		},
+	}
+
+	for i, s := range scenarioTable {
+		ran = ""
+		p.resetKeySequence()
+		var handled [][]byte
+		for _, b := range s.input {
+			handled = append(handled, p.matchKeySequence(b)...)
+		}
+		if !equalChunks(handled, s.handled) {
+			t.Errorf("%d: Want %q to be handled as single keys, but got %q", i, s.handled, handled)
+		}
+		if p.renderer.pendingKeys != s.pending {
+			t.Errorf("%d: Want %q pending, but got %q", i, s.pending, p.renderer.pendingKeys)
+		}
+		if ran != s.ran {
+			t.Errorf("%d: Want %q to run, but got %q", i, s.ran, ran)
+		}
+	}
+}
+
+func equalChunks(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestExpireKeySequence(t *testing.T) {
+	ran := false
+	p := &Prompt{renderer: &Render{}, buf: NewBuffer(), keySequenceTimeout: time.Millisecond}
+	p.keymap = newKeymap()
+	p.keymap.bind([]Key{ControlX}, func(*Prompt) { ran = true })
+	p.keymap.bind([]Key{ControlX, ControlE}, func(*Prompt) {})
+	p.keymap.bind([]Key{ControlG, ControlE}, func(*Prompt) {})
+
+	p.matchKeySequence([]byte{0x18})
+	time.Sleep(2 * time.Millisecond)
+	if !p.keySequenceExpired() {
+		t.Fatalf("Want the sequence to expire")
+	}
+	if keys := p.expireKeySequence(); len(keys) != 0 || !ran {
+		t.Errorf("Want the prefix bound to run, but got %q handled", keys)
+	}
+
+	p.matchKeySequence([]byte{0x7})
+	time.Sleep(2 * time.Millisecond)
+	if keys := p.expireKeySequence(); !equalChunks(keys, [][]byte{{0x7}}) {
+		t.Errorf("Want the prefix handled as a single key, but got %q", keys)
+	}
+	if p.keySequenceExpired() {
+		t.Errorf("Want no sequence pending")
+	}
+}
+
+func TestPromptKeySequences(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		for _, s := range []string{"\x1b[1;1R", "abc", "d", "\x18", "\x15", "\x18", "\x05", "\x18", "q"} {
+			w.Write([]byte(s))
+		}
+		w.Close()
+	}()
+	var out bytes.Buffer
+	var edited string
+	p := New(nil, func(d Document, results chan []Suggest) { results <- nil },
+		OptionParser(NewStreamParser(r)),
+		OptionWriter(NewStreamWriter(&out)),
+		OptionSignals(),
+		OptionEditor(func(text string) (string, error) {
+			edited = text
+			return text + "!", nil
+		}),
+	)
+	p.Run()
+
+	if edited != "abc" {
+		t.Errorf("Want %q to be edited after the undo, but got %q", "abc", edited)
+	}
+	if text := p.buf.Text(); text != "abc!q" {
+		t.Errorf("Want %q, but got %q", "abc!q", text)
+	}
+	if !strings.Contains(out.String(), "C-x-") {
+		t.Errorf("Should show the pending keys in the status bar, but got %q", out.String())
+	}
+}
+
+func TestBuildKeymap(t *testing.T) {
+	bound := func(m *keymap, keys ...Key) bool {
+		for _, k := range keys {
+			if m = m.children[k]; m == nil {
+				return false
+			}
+		}
+		return m.fn != nil
+	}
+
+	// The editor isn't run for prompts that don't read the terminal of the process.
+	p := New(nil, nil, OptionParser(NewStreamParser(strings.NewReader(""))))
+	m := p.buildKeymap()
+	if !bound(m, ControlX, ControlU) || bound(m, ControlX, ControlE) {
+		t.Errorf("Want Ctrl+x Ctrl+u bound without Ctrl+x Ctrl+e")
+	}
+
+	// A key bind of Ctrl+x keeps working as before.
+	p = New(nil, nil,
+		OptionParser(NewStreamParser(strings.NewReader(""))),
+		OptionEditor(func(text string) (string, error) { return text, nil }),
+		OptionAddKeyBind(KeyBind{Key: ControlX, Fn: func(*Buffer) {}}),
+	)
+	if m := p.buildKeymap(); len(m.children) != 0 {
+		t.Errorf("Want no key sequence bound, but got %d", len(m.children))
+	}
+
+	// The changes of custom key sequences can be undone.
+	p = New(nil, nil,
+		OptionParser(NewStreamParser(strings.NewReader(""))),
+		OptionAddKeySequenceBind(KeySequenceBind{
+			Keys: []Key{ControlX, ControlT},
+			Fn:   func(b *Buffer) { b.InsertText("text", false, true) },
+		}),
+	)
+	p.keymap = p.buildKeymap()
+	p.matchKeySequence([]byte{0x18})
+	p.matchKeySequence([]byte{0x14})
+	if text := p.buf.Text(); text != "text" {
+		t.Fatalf("Want %q, but got %q", "text", text)
+	}
+	p.matchKeySequence([]byte{0x18})
+	p.matchKeySequence([]byte{0x15})
+	if text := p.buf.Text(); text != "" {
+		t.Errorf("Should undo the key sequence, but got %q", text)
+	}
+}
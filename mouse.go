@@ -0,0 +1,119 @@
+package prompt
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// MouseButton is the button of a mouse event.
+type MouseButton int
+
+const (
+	// MouseLeft is the left button.
+	MouseLeft MouseButton = iota
+	// MouseMiddle is the middle button.
+	MouseMiddle
+	// MouseRight is the right button.
+	MouseRight
+	// MouseNoButton is reported when the mouse moves with no button pressed.
+	MouseNoButton
+	// MouseWheelUp is the wheel scrolled up.
+	MouseWheelUp
+	// MouseWheelDown is the wheel scrolled down.
+	MouseWheelDown
+)
+
+// MouseEvent is a mouse event reported by the terminal.
+type MouseEvent struct {
+	Button MouseButton
+	// Pressed is false when the button is released.
+	Pressed bool
+	// Motion is true when the mouse moved while the button was pressed.
+	Motion bool
+	// X and Y are the column and the row of the event, counted from 0 at the top left corner of the screen.
+	X, Y    int
+	Shift   bool
+	Alt     bool
+	Control bool
+}
+
+var mouseSequencePrefix = []byte{0x1b, '[', '<'}
+
+// ParseMouseEvents decodes b when it's made of mouse events in the SGR format, ESC [ < b ; x ; y M or m.
+// Terminals may send several events at once, while the wheel spins for example.
+func ParseMouseEvents(b []byte) ([]MouseEvent, bool) {
+	var events []MouseEvent
+	for len(b) > 0 {
+		if !bytes.HasPrefix(b, mouseSequencePrefix) {
+			return nil, false
+		}
+		end := bytes.IndexAny(b, "Mm")
+		if end < 0 {
+			return nil, false
+		}
+		params := bytes.Split(b[len(mouseSequencePrefix):end], []byte{';'})
+		if len(params) != 3 {
+			return nil, false
+		}
+		var n [3]int
+		for i, p := range params {
+			v, err := strconv.Atoi(string(p))
+			if err != nil {
+				return nil, false
+			}
+			n[i] = v
+		}
+
+		e := MouseEvent{
+			Pressed: b[end] == 'M',
+			Motion:  n[0]&32 != 0,
+			X:       n[1] - 1,
+			Y:       n[2] - 1,
+			Shift:   n[0]&4 != 0,
+			Alt:     n[0]&8 != 0,
+			Control: n[0]&16 != 0,
+		}
+		switch button := n[0] & 3; {
+		case n[0]&64 != 0 && button == 0:
+			e.Button = MouseWheelUp
+		case n[0]&64 != 0:
+			e.Button = MouseWheelDown
+		default:
+			e.Button = MouseButton(button)
+		}
+		events = append(events, e)
+		b = b[end+1:]
+	}
+	return events, len(events) > 0
+}
+
+// handleMouse handles the mouse events in b. A click in the input moves the cursor there,
+// a click on a suggestion accepts it, and the wheel scrolls the completion drop down.
+func (p *Prompt) handleMouse(b []byte) {
+	events, _ := ParseMouseEvents(b)
+	for _, e := range events {
+		switch {
+		case e.Button == MouseWheelUp && e.Pressed:
+			p.completion.Scroll(-1)
+		case e.Button == MouseWheelDown && e.Pressed:
+			p.completion.Scroll(1)
+		case e.Button == MouseLeft && e.Pressed && !e.Motion:
+			x, y, ok := p.renderer.framePosition(e.X, e.Y)
+			if !ok {
+				continue
+			}
+			if i, ok := p.renderer.suggestionAt(x, y); ok {
+				if !p.completion.isHeader(i) {
+					p.completion.selected = i
+					p.acceptCompletion()
+				}
+				continue
+			}
+			// The frame shows the selected suggestion as if it was accepted.
+			p.acceptCompletion()
+			if i, ok := p.renderer.inputIndexAt(x, y, p.buf.Text()); ok {
+				p.buf.setCursorPosition(i)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMouseEvents(t *testing.T) {
+	scenarioTable := []struct {
+		in       string
+		expected []MouseEvent
+		ok       bool
+	}{
+		{
+			in:       "\x1b[<0;12;3M",
+			expected: []MouseEvent{{Button: MouseLeft, Pressed: true, X: 11, Y: 2}},
+			ok:       true,
+		},
+		{
+			in:       "\x1b[<18;1;1m",
+			expected: []MouseEvent{{Button: MouseRight, X: 0, Y: 0, Control: true}},
+			ok:       true,
+		},
+		{
+			in: "\x1b[<64;5;5M\x1b[<65;5;5M",
+			expected: []MouseEvent{
+				{Button: MouseWheelUp, Pressed: true, X: 4, Y: 4},
+				{Button: MouseWheelDown, Pressed: true, X: 4, Y: 4},
+			},
+			ok: true,
+		},
+		{
+			in:       "\x1b[<32;2;3M",
+			expected: []MouseEvent{{Button: MouseLeft, Pressed: true, Motion: true, X: 1, Y: 2}},
+			ok:       true,
+		},
+		{
+			in: "\x1b[<0;12M",
+		},
+		{
+			in: "\x1b[<0;12;3Mabc",
+		},
+		{
+			in: "\x1b[A",
+		},
+	}
+
+	for _, s := range scenarioTable {
+		actual, ok := ParseMouseEvents([]byte(s.in))
+		if ok != s.ok || !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("%q: should be %#v, %v, but got %#v, %v", s.in, s.expected, s.ok, actual, ok)
+		}
+	}
+}
+
+func TestPromptHandleMouse(t *testing.T) {
+	p := &Prompt{
+		buf: NewBuffer(),
+		renderer: &Render{
+			out:                &bufferWriter{},
+			prefix:             "> ",
+			livePrefixCallback: func() (string, bool) { return "", false },
+			theme:              DefaultTheme(),
+			fullScreen:         true,
+			mouse:              true,
+			row:                20,
+			col:                40,
+		},
+		completion: NewCompletionManager(nil, 2),
+	}
+	p.completion.SetResults([]Suggest{{Text: "select"}, {Text: "from"}, {Text: "where"}})
+	p.buf.InsertText("find me", false, true)
+	p.renderer.Render(p.buf, p.completion)
+	// The prompt takes the last 3 rows: the input line, and 2 rows for the drop down.
+
+	p.feed([]byte("\x1b[<0;5;18M"))
+	if p.buf.cursorPosition != 2 {
+		t.Errorf("Should move the cursor where the input was clicked, but got %d", p.buf.cursorPosition)
+	}
+
+	p.feed([]byte("\x1b[<65;1;1M"))
+	if p.completion.verticalScroll != 1 {
+		t.Errorf("Should scroll the drop down, but got %d", p.completion.verticalScroll)
+	}
+	p.renderer.Render(p.buf, p.completion)
+
+	p.feed([]byte("\x1b[<0;5;20M\x1b[<0;5;20m"))
+	if text := p.buf.Text(); text != "where" {
+		t.Errorf("Should accept the clicked suggestion, but got %q", text)
+	}
+}
@@ -0,0 +1,57 @@
+package prompt
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aschey/go-prompt/internal/debug"
+	isatty "github.com/mattn/go-isatty"
+)
+
+// stdinIsTerminal tells whether the standard input is a terminal, rather than a pipe or a file.
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// terminalParser is a parser reading the terminal of the process, which may have none.
+type terminalParser interface {
+	// hasTerminal tells whether there is a terminal to read.
+	hasTerminal() bool
+}
+
+// runNonInteractive reads the input line by line from r, without raw mode or rendering,
+// and passes each line to the executor. It returns 0 at the end of the input, like Ctrl+d does.
+func (p *Prompt) runNonInteractive(r io.Reader) int {
+	defer debug.Teardown()
+	debug.Log("start non-interactive prompt")
+	// Nothing is styled, as the output is likely not a terminal either.
+	p.renderer.monochrome = true
+
+	in := bufio.NewReader(r)
+	for {
+		line, err := in.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				debug.Log(err.Error())
+			}
+			return 0
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if p.exitChecker != nil && p.exitChecker(line, false) {
+			return 0
+		}
+		if line != "" {
+			p.history.Add(line)
+		}
+		if exit, code := p.execute(line, nil, nil); exit {
+			return code
+		}
+		if p.exitChecker != nil && p.exitChecker(line, true) {
+			return 0
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunNonInteractive(t *testing.T) {
+	scenarioTable := []struct {
+		script   string
+		exit     ExitChecker
+		code     int
+		expected []string
+	}{
+		{
+			script:   "select 1;\r\n\nselect 2;",
+			expected: []string{"select 1;", "", "select 2;"},
+		},
+		{
+			script:   "select 1;\nquit\nselect 2;\n",
+			exit:     func(in string, breakline bool) bool { return !breakline && in == "quit" },
+			expected: []string{"select 1;"},
+		},
+		{
+			script:   "select 1;\nquit\nselect 2;\n",
+			exit:     func(in string, breakline bool) bool { return breakline && in == "quit" },
+			expected: []string{"select 1;", "quit"},
+		},
+		{
+			script:   "select 1;\nexit 3\nselect 2;\n",
+			code:     3,
+			expected: []string{"select 1;", "exit 3"},
+		},
+	}
+
+	for i, s := range scenarioTable {
+		var executed []string
+		p := New(nil, nil,
+			OptionNonInteractive(strings.NewReader(s.script)),
+			OptionSetExitCheckerOnInput(s.exit),
+			OptionExecutorContext(func(_ context.Context, in string, _ *Suggest, _ []Suggest) error {
+				executed = append(executed, in)
+				if in == "exit 3" {
+					return &ExitError{Code: 3}
+				}
+				return nil
+			}),
+		)
+		if code := p.Run(); code != s.code {
+			t.Errorf("[scenario %d] Want exit code %d, but got %d", i, s.code, code)
+		}
+		if !reflect.DeepEqual(executed, s.expected) {
+			t.Errorf("[scenario %d] Want %#v, but got %#v", i, s.expected, executed)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package prompt
 
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
 // Option is the type to replace default parameters.
 // prompt.New accepts any number of options (this is functional option pattern).
 type Option func(prompt *Prompt) error
@@ -15,12 +22,47 @@ func OptionParser(x ConsoleParser) Option {
 // OptionWriter to set a custom ConsoleWriter object. An argument should implement ConsoleWriter interface.
 func OptionWriter(x ConsoleWriter) Option {
 	return func(p *Prompt) error {
-		registerConsoleWriter(x)
 		p.renderer.out = x
 		return nil
 	}
 }
 
+// OptionTheme to set the styles of every element of the prompt.
+// DefaultTheme, DarkTheme, LightTheme and HighContrastTheme are built in, and LoadThemeFile reads one from a file.
+func OptionTheme(x Theme) Option {
+	return func(p *Prompt) error {
+		p.renderer.theme = x
+		return nil
+	}
+}
+
+// OptionThemeSignal to switch the theme while the prompt is running by sending it to themeChan.
+func OptionThemeSignal(themeChan chan Theme) Option {
+	return func(p *Prompt) error {
+		p.themeChan = themeChan
+		return nil
+	}
+}
+
+// OptionMonochrome to draw the prompt without any color or display attribute.
+// The selected suggestion is marked with ">" instead.
+// It's enabled by default when NO_COLOR is set or TERM is dumb.
+func OptionMonochrome(x bool) Option {
+	return func(p *Prompt) error {
+		p.renderer.monochrome = x
+		return nil
+	}
+}
+
+// OptionColorDepth to set how many colors the terminal supports instead of detecting it from COLORTERM and TERM.
+// It applies to writers built on VT100Writer.
+func OptionColorDepth(x ColorDepth) Option {
+	return func(p *Prompt) error {
+		p.renderer.colorDepth = x
+		return nil
+	}
+}
+
 // OptionTitle to set title displayed at the header bar of terminal.
 func OptionTitle(x string) Option {
 	return func(p *Prompt) error {
@@ -61,130 +103,360 @@ func OptionLivePrefix(f func() (prefix string, useLivePrefix bool)) Option {
 	}
 }
 
+// OptionLivePrefixStatus to change the prefix dynamically by callback function,
+// which is given the exit status of the last executed input.
+// The status is set by an ExecutorContext, see OptionExecutorContext, and is 0 with an Executor.
+func OptionLivePrefixStatus(f func(status int) (prefix string, useLivePrefix bool)) Option {
+	return func(p *Prompt) error {
+		r := p.renderer
+		r.livePrefixCallback = func() (string, bool) { return f(r.status) }
+		return nil
+	}
+}
+
+// OptionPrefixSegments to set a prefix made of several styled segments.
+// Line breaks in the segments put the text before them on lines above the input.
+func OptionPrefixSegments(x ...PrefixSegment) Option {
+	return func(p *Prompt) error {
+		p.renderer.prefixSegments = x
+		return nil
+	}
+}
+
+// OptionLivePrefixSegments to change the styled segments of the prefix dynamically by callback function
+func OptionLivePrefixSegments(f func() (segments []PrefixSegment, useLivePrefix bool)) Option {
+	return func(p *Prompt) error {
+		p.renderer.livePrefixSegmentsCallback = f
+		return nil
+	}
+}
+
+// OptionTransientPrefix to draw the accepted line again with x as its prefix before breaking the line,
+// so that large prompts don't clutter the scrollback.
+func OptionTransientPrefix(x string) Option {
+	return func(p *Prompt) error {
+		p.renderer.transient = true
+		p.renderer.transientPrefix = x
+		return nil
+	}
+}
+
+// OptionTransientPlainInput to draw the accepted line without the input style before breaking the line.
+func OptionTransientPlainInput() Option {
+	return func(p *Prompt) error {
+		p.renderer.transientPlainInput = true
+		return nil
+	}
+}
+
+// OptionFullScreen to run the prompt on the alternate screen, which it owns until it exits.
+// The input stays at the bottom of the screen with room below it for the completion drop down,
+// and output scrolls in the region above. The original screen is restored on exit.
+// It requires a writer implementing FullScreenWriter, and is ignored otherwise.
+func OptionFullScreen() Option {
+	return func(p *Prompt) error {
+		p.renderer.fullScreen = true
+		return nil
+	}
+}
+
+// OptionMouse to enable the mouse: a click in the input moves the cursor, a click on a suggestion accepts it,
+// and the wheel scrolls the completion drop down. Clicks are located once the row the prompt starts on
+// is known, as it always is in full screen mode.
+// It requires a writer implementing MouseWriter, and is ignored otherwise.
+func OptionMouse() Option {
+	return func(p *Prompt) error {
+		p.renderer.mouse = true
+		return nil
+	}
+}
+
+// OptionJobControl to stop the process on Ctrl+z, restoring the terminal first,
+// and to draw the prompt again when it's continued. It has no effect on Windows.
+func OptionJobControl() Option {
+	return func(p *Prompt) error {
+		p.jobControl = true
+		return nil
+	}
+}
+
+// OptionExecutorContext to run fn instead of the Executor given to New.
+// Ctrl+c while it runs goes to the interrupt handler, which cancels its context by default, instead of killing the process.
+func OptionExecutorContext(fn ExecutorContext) Option {
+	return func(p *Prompt) error {
+		p.executorContext = fn
+		return nil
+	}
+}
+
+// OptionErrorHandler to show the errors returned by the ExecutorContext with fn.
+// By default, they are written on their own line in the error style of the theme.
+func OptionErrorHandler(fn ErrorHandler) Option {
+	return func(p *Prompt) error {
+		p.errorHandler = fn
+		return nil
+	}
+}
+
+// OptionNonInteractive to read the input line by line from r, without raw mode or rendering,
+// passing each line to the executor. The prompt does so with the standard input when there is no terminal
+// to read the keys from, like when run from cron with a script piped in, unless a parser is set with OptionParser,
+// or r is nil.
+func OptionNonInteractive(r io.Reader) Option {
+	return func(p *Prompt) error {
+		p.script = r
+		p.interactive = r == nil
+		return nil
+	}
+}
+
+// OptionIdleHandler to call fn on the goroutine of Run whenever the prompt is idle: it handled and drew every input
+// it read, and isn't waiting for suggestions. reads is the number of inputs read from the parser since Run started.
+// It's meant for tests driving the prompt, like the ones of the prompttest package.
+func OptionIdleHandler(fn func(reads int)) Option {
+	return func(p *Prompt) error {
+		p.idleHandler = fn
+		return nil
+	}
+}
+
+// OptionRecorder to record the session with r, whatever parser the prompt uses.
+// The output is recorded with the writers built on VT100Writer, like the ones of this package.
+func OptionRecorder(r *Recorder) Option {
+	return func(p *Prompt) error {
+		p.recorder = r
+		return nil
+	}
+}
+
+// OptionSignals to choose the signals the prompt subscribes to.
+// Only SIGINT, SIGTERM, SIGQUIT and SIGWINCH are handled, and no signals at all are subscribed to with an empty list.
+// By default, all of them are. Other signals are an error, as the prompt would take them from the process
+// only to drop them.
+func OptionSignals(signals ...os.Signal) Option {
+	return func(p *Prompt) error {
+		for _, sig := range signals {
+			if !signalSupported(sig) {
+				return fmt.Errorf("unsupported signal %v", sig)
+			}
+		}
+		if signals == nil {
+			signals = []os.Signal{}
+		}
+		p.signals = signals
+		return nil
+	}
+}
+
+// OptionInterruptHandler to decide what Ctrl+c and SIGINT do.
+// By default, Ctrl+c cancels the line and SIGINT exits with code 0.
+func OptionInterruptHandler(fn InterruptHandler) Option {
+	return func(p *Prompt) error {
+		p.interruptHandler = fn
+		return nil
+	}
+}
+
+// OptionTerminateHandler to decide what SIGTERM does. By default, it exits with code 1.
+func OptionTerminateHandler(fn SignalHandler) Option {
+	return func(p *Prompt) error {
+		p.terminateHandler = fn
+		return nil
+	}
+}
+
+// OptionQuitHandler to decide what SIGQUIT does. By default, it exits with code 0.
+func OptionQuitHandler(fn SignalHandler) Option {
+	return func(p *Prompt) error {
+		p.quitHandler = fn
+		return nil
+	}
+}
+
+// OptionRPrompt to set a prompt shown at the right edge of the input line.
+// It's hidden while the input is too long for both to fit.
+func OptionRPrompt(x string) Option {
+	return func(p *Prompt) error {
+		p.renderer.rprompt = x
+		return nil
+	}
+}
+
+// OptionLiveRPrompt to change the right prompt dynamically by callback function
+func OptionLiveRPrompt(f func() (rprompt string, useLiveRPrompt bool)) Option {
+	return func(p *Prompt) error {
+		p.renderer.liveRPromptCallback = f
+		return nil
+	}
+}
+
 // OptionPrefixTextColor change a text color of prefix string
+//
+// Deprecated: Please use OptionTheme.
 func OptionPrefixTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.prefixTextColor = x
+		p.renderer.theme.Prefix.TextColor = x
+		p.renderer.theme.Placeholder.TextColor = x
 		return nil
 	}
 }
 
 // OptionPrefixBackgroundColor to change a background color of prefix string
+//
+// Deprecated: Please use OptionTheme.
 func OptionPrefixBackgroundColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.prefixBGColor = x
+		p.renderer.theme.Prefix.BGColor = x
+		p.renderer.theme.Placeholder.BGColor = x
 		return nil
 	}
 }
 
 // OptionInputTextColor to change a color of text which is input by user
+//
+// Deprecated: Please use OptionTheme.
 func OptionInputTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.inputTextColor = x
+		p.renderer.theme.Input.TextColor = x
 		return nil
 	}
 }
 
 // OptionInputBGColor to change a color of background which is input by user
+//
+// Deprecated: Please use OptionTheme.
 func OptionInputBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.inputBGColor = x
+		p.renderer.theme.Input.BGColor = x
 		return nil
 	}
 }
 
 // OptionPreviewSuggestionTextColor to change a text color which is completed
+//
+// Deprecated: Please use OptionTheme.
 func OptionPreviewSuggestionTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.previewSuggestionTextColor = x
+		p.renderer.theme.PreviewSuggestion.TextColor = x
 		return nil
 	}
 }
 
 // OptionPreviewSuggestionBGColor to change a background color which is completed
+//
+// Deprecated: Please use OptionTheme.
 func OptionPreviewSuggestionBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.previewSuggestionBGColor = x
+		p.renderer.theme.PreviewSuggestion.BGColor = x
 		return nil
 	}
 }
 
 // OptionSuggestionTextColor to change a text color in drop down suggestions.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSuggestionTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.suggestionTextColor = x
+		p.renderer.theme.Suggestion.TextColor = x
 		return nil
 	}
 }
 
 // OptionSuggestionBGColor change a background color in drop down suggestions.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSuggestionBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.suggestionBGColor = x
+		p.renderer.theme.Suggestion.BGColor = x
 		return nil
 	}
 }
 
 // OptionSelectedSuggestionTextColor to change a text color for completed text which is selected inside suggestions drop down box.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSelectedSuggestionTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.selectedSuggestionTextColor = x
+		p.renderer.theme.SelectedSuggestion.TextColor = x
 		return nil
 	}
 }
 
 // OptionSelectedSuggestionBGColor to change a background color for completed text which is selected inside suggestions drop down box.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSelectedSuggestionBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.selectedSuggestionBGColor = x
+		p.renderer.theme.SelectedSuggestion.BGColor = x
 		return nil
 	}
 }
 
 // OptionDescriptionTextColor to change a background color of description text in drop down suggestions.
+//
+// Deprecated: Please use OptionTheme.
 func OptionDescriptionTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.descriptionTextColor = x
+		p.renderer.theme.Description.TextColor = x
 		return nil
 	}
 }
 
 // OptionDescriptionBGColor to change a background color of description text in drop down suggestions.
+//
+// Deprecated: Please use OptionTheme.
 func OptionDescriptionBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.descriptionBGColor = x
+		p.renderer.theme.Description.BGColor = x
 		return nil
 	}
 }
 
 // OptionSelectedDescriptionTextColor to change a text color of description which is selected inside suggestions drop down box.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSelectedDescriptionTextColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.selectedDescriptionTextColor = x
+		p.renderer.theme.SelectedDescription.TextColor = x
 		return nil
 	}
 }
 
 // OptionSelectedDescriptionBGColor to change a background color of description which is selected inside suggestions drop down box.
+//
+// Deprecated: Please use OptionTheme.
 func OptionSelectedDescriptionBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.selectedDescriptionBGColor = x
+		p.renderer.theme.SelectedDescription.BGColor = x
 		return nil
 	}
 }
 
 // OptionScrollbarThumbColor to change a thumb color on scrollbar.
+//
+// Deprecated: Please use OptionTheme.
 func OptionScrollbarThumbColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.scrollbarThumbColor = x
+		p.renderer.theme.ScrollbarThumb.BGColor = x
 		return nil
 	}
 }
 
 // OptionScrollbarBGColor to change a background color of scrollbar.
+//
+// Deprecated: Please use OptionTheme.
 func OptionScrollbarBGColor(x Color) Option {
 	return func(p *Prompt) error {
-		p.renderer.scrollbarBGColor = x
+		p.renderer.theme.ScrollbarBG.BGColor = x
+		return nil
+	}
+}
+
+// OptionDocumentation to show the documentation of the selected suggestion next to the drop down.
+// fn is only called for the suggestion that gets selected. The documentation is scrolled with Shift+Up and Shift+Down.
+func OptionDocumentation(fn DocumentationLoader) Option {
+	return func(p *Prompt) error {
+		p.completion.documentationLoader = fn
 		return nil
 	}
 }
@@ -234,6 +506,39 @@ func OptionAddKeyBind(b ...KeyBind) Option {
 	}
 }
 
+// OptionAddKeySequenceBind to set a custom key bind to a sequence of keys, such as Ctrl+x Ctrl+e.
+// The keys of a sequence are shown in the status bar while it's pending. In the emacs key bind mode,
+// Ctrl+x Ctrl+u undoes the last change and Ctrl+x Ctrl+e edits the input in an editor, see OptionEditor,
+// unless a custom key bind takes Ctrl+x.
+func OptionAddKeySequenceBind(b ...KeySequenceBind) Option {
+	return func(p *Prompt) error {
+		p.keySequenceBindings = append(p.keySequenceBindings, b...)
+		return nil
+	}
+}
+
+// OptionKeySequenceTimeout to set how long to wait for the next key of a sequence.
+// The keys read so far are handled as single keys when it runs out, unless they are a sequence themselves.
+func OptionKeySequenceTimeout(d time.Duration) Option {
+	return func(p *Prompt) error {
+		p.keySequenceTimeout = d
+		return nil
+	}
+}
+
+// OptionEditor to edit the input with fn on Ctrl+x Ctrl+e in the emacs key bind mode. fn is given the input,
+// and returns what to replace it with. The terminal is restored while it runs.
+// By default, the input is edited in $VISUAL or $EDITOR on the terminal of the process, only when the prompt
+// reads it with the default parser.
+// A nil fn leaves Ctrl+x Ctrl+e unbound.
+func OptionEditor(fn func(text string) (string, error)) Option {
+	return func(p *Prompt) error {
+		p.editor = fn
+		p.editorSet = true
+		return nil
+	}
+}
+
 // OptionAddASCIICodeBind to set a custom key bind.
 func OptionAddASCIICodeBind(b ...ASCIICodeBind) Option {
 	return func(p *Prompt) error {
@@ -292,36 +597,25 @@ func OptionStatusbarSignal(statusbarChan chan string) Option {
 // New returns a Prompt with powerful auto-completion.
 func New(executor Executor, completer Completer, opts ...Option) *Prompt {
 	defaultWriter := NewStdoutWriter()
-	registerConsoleWriter(defaultWriter)
 
 	pt := &Prompt{
-		in: NewStandardInputParser(),
 		renderer: &Render{
-			prefix:                       "> ",
-			out:                          defaultWriter,
-			livePrefixCallback:           func() (string, bool) { return "", false },
-			prefixTextColor:              Blue,
-			prefixBGColor:                DefaultColor,
-			inputTextColor:               DefaultColor,
-			inputBGColor:                 DefaultColor,
-			previewSuggestionTextColor:   Green,
-			previewSuggestionBGColor:     DefaultColor,
-			suggestionTextColor:          White,
-			suggestionBGColor:            Cyan,
-			selectedSuggestionTextColor:  Black,
-			selectedSuggestionBGColor:    Turquoise,
-			descriptionTextColor:         Black,
-			descriptionBGColor:           Turquoise,
-			selectedDescriptionTextColor: White,
-			selectedDescriptionBGColor:   Cyan,
-			scrollbarThumbColor:          DarkGray,
-			scrollbarBGColor:             Cyan,
+			prefix:             "> ",
+			out:                defaultWriter,
+			livePrefixCallback: func() (string, bool) { return "", false },
+			theme:              DefaultTheme(),
+			monochrome:         monochromeRequested(),
 		},
 		buf:         NewBuffer(),
 		executor:    executor,
 		history:     NewHistory(),
 		completion:  NewCompletionManager(completer, 6),
 		keyBindMode: EmacsKeyBind, // All the above assume that bash is running in the default Emacs setting
+		printCh:     make(chan struct{}, 1),
+		controlCh:   make(chan func(*controlResult), 64),
+		exitCh:      make(chan int, 1),
+
+		keySequenceTimeout: DefaultKeySequenceTimeout,
 	}
 
 	for _, opt := range opts {
@@ -329,5 +623,21 @@ func New(executor Executor, completer Completer, opts ...Option) *Prompt {
 			panic(err)
 		}
 	}
+	// The terminal is only opened when no parser was set, as nothing would close it otherwise.
+	defaultParser := pt.in == nil
+	if defaultParser {
+		pt.in = NewStandardInputParser()
+	}
+	if t, ok := pt.in.(terminalParser); ok && pt.script == nil && !pt.interactive && defaultParser &&
+		!t.hasTerminal() {
+		pt.script = os.Stdin
+	}
+	if !pt.editorSet && defaultParser {
+		// The editor runs on the terminal of the process, which other parsers may not read.
+		pt.editor = editText
+	}
+	if pt.recorder != nil {
+		pt.recorder.wrap(pt)
+	}
 	return pt
 }
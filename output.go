@@ -1,18 +1,5 @@
 package prompt
 
-import "sync"
-
-var (
-	consoleWriterMu sync.Mutex
-	consoleWriter   ConsoleWriter
-)
-
-func registerConsoleWriter(f ConsoleWriter) {
-	consoleWriterMu.Lock()
-	defer consoleWriterMu.Unlock()
-	consoleWriter = f
-}
-
 // DisplayAttribute represents display  attributes like Blinking, Bold, Italic and so on.
 type DisplayAttribute int
 
@@ -159,3 +146,34 @@ type ConsoleWriter interface {
 	// SetColor sets text and background colors. and specify whether text is bold.
 	SetColor(fg, bg Color, bold bool)
 }
+
+// DisplayAttributesWriter is a ConsoleWriter that can set display attributes other than bold,
+// like italic or underline. Other writers draw styles with SetColor.
+type DisplayAttributesWriter interface {
+	ConsoleWriter
+	// SetDisplayAttributes sets text and background colors along with the given display attributes.
+	SetDisplayAttributes(fg, bg Color, attrs ...DisplayAttribute)
+}
+
+// FullScreenWriter is a ConsoleWriter that can draw on the alternate screen below a scrolling region,
+// as OptionFullScreen requires.
+type FullScreenWriter interface {
+	ConsoleWriter
+	// SetScrollingRegion limits scrolling to the rows from top to bottom, counted from 1.
+	SetScrollingRegion(top, bottom int)
+	// ResetScrollingRegion lets the whole screen scroll again.
+	ResetScrollingRegion()
+	// EnterAlternateScreen switches to the alternate screen buffer, saving the main screen.
+	EnterAlternateScreen()
+	// ExitAlternateScreen switches back to the main screen buffer and restores it.
+	ExitAlternateScreen()
+}
+
+// MouseWriter is a ConsoleWriter that can have the terminal report mouse events, as OptionMouse requires.
+type MouseWriter interface {
+	ConsoleWriter
+	// EnableMouse asks the terminal to report mouse events in the SGR format.
+	EnableMouse()
+	// DisableMouse stops the reporting of mouse events.
+	DisableMouse()
+}
@@ -7,7 +7,22 @@ import (
 
 // VT100Writer generates VT100 escape sequences.
 type VT100Writer struct {
-	buffer []byte
+	buffer     []byte
+	colorDepth ColorDepth
+}
+
+// SetColorDepth sets how many colors the terminal supports.
+// Colors beyond it are replaced with the nearest supported one.
+// ColorDepthAuto, the default, detects it from the environment.
+func (w *VT100Writer) SetColorDepth(d ColorDepth) {
+	w.colorDepth = d
+}
+
+func (w *VT100Writer) depth() ColorDepth {
+	if w.colorDepth == ColorDepthAuto {
+		w.colorDepth = DetectColorDepth()
+	}
+	return w.colorDepth
 }
 
 // WriteRaw to write raw byte array
@@ -174,6 +189,46 @@ func (w *VT100Writer) ScrollUp() {
 	w.WriteRaw([]byte{0x1b, 'M'})
 }
 
+// SetScrollingRegion limits scrolling to the rows from top to bottom, counted from 1.
+func (w *VT100Writer) SetScrollingRegion(top, bottom int) {
+	w.WriteRaw([]byte{0x1b, '['})
+	w.WriteRaw([]byte(strconv.Itoa(top)))
+	w.WriteRaw([]byte{';'})
+	w.WriteRaw([]byte(strconv.Itoa(bottom)))
+	w.WriteRaw([]byte{'r'})
+}
+
+// ResetScrollingRegion lets the whole screen scroll again.
+func (w *VT100Writer) ResetScrollingRegion() {
+	w.WriteRaw([]byte{0x1b, '[', 'r'})
+}
+
+/* Screen */
+
+// EnterAlternateScreen switches to the alternate screen buffer, saving the main screen.
+func (w *VT100Writer) EnterAlternateScreen() {
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '4', '9', 'h'})
+}
+
+// ExitAlternateScreen switches back to the main screen buffer and restores it.
+func (w *VT100Writer) ExitAlternateScreen() {
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '4', '9', 'l'})
+}
+
+/* Mouse */
+
+// EnableMouse asks the terminal to report mouse events in the SGR format.
+func (w *VT100Writer) EnableMouse() {
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '0', '0', 'h'})
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '0', '6', 'h'})
+}
+
+// DisableMouse stops the reporting of mouse events.
+func (w *VT100Writer) DisableMouse() {
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '0', '6', 'l'})
+	w.WriteRaw([]byte{0x1b, '[', '?', '1', '0', '0', '0', 'l'})
+}
+
 /* Title */
 
 // SetTitle sets a title of terminal window.
@@ -234,17 +289,30 @@ func (w *VT100Writer) SetDisplayAttributes(fg, bg Color, attrs ...DisplayAttribu
 		w.WriteRaw([]byte{separator})
 	}
 
-	f, ok := foregroundANSIColors[fg]
-	if !ok {
-		f = foregroundANSIColors[DefaultColor]
-	}
-	w.WriteRaw(f)
+	w.writeColor(fg, foregroundANSIColors, '3')
 	w.WriteRaw([]byte{separator})
-	b, ok := backgroundANSIColors[bg]
-	if !ok {
-		b = backgroundANSIColors[DefaultColor]
+	w.writeColor(bg, backgroundANSIColors, '4')
+}
+
+// writeColor writes the parameters of c, degraded to the color depth of the terminal.
+// introducer is '3' for the foreground and '4' for the background.
+func (w *VT100Writer) writeColor(c Color, ansi map[Color][]byte, introducer byte) {
+	c = c.convert(w.depth())
+	switch c & colorKindMask {
+	case color256Kind:
+		w.WriteRaw([]byte{introducer, '8', ';', '5', ';'})
+		w.WriteRaw([]byte(strconv.Itoa(int(c &^ colorKindMask))))
+	case colorRGBKind:
+		r, g, b := c.rgb()
+		w.WriteRaw([]byte{introducer, '8', ';', '2', ';'})
+		w.WriteRaw([]byte(strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b))))
+	default:
+		p, ok := ansi[c]
+		if !ok {
+			p = ansi[DefaultColor]
+		}
+		w.WriteRaw(p)
 	}
-	w.WriteRaw(b)
 }
 
 var displayAttributeParameters = map[DisplayAttribute][]byte{
@@ -79,3 +79,47 @@ func TestVT100WriterWriteRawStr(t *testing.T) {
 		}
 	}
 }
+
+func TestVT100WriterSetDisplayAttributes(t *testing.T) {
+	scenarioTable := []struct {
+		fg       Color
+		bg       Color
+		depth    ColorDepth
+		expected string
+	}{
+		{
+			fg:       Red,
+			bg:       DefaultColor,
+			depth:    ColorDepthTrueColor,
+			expected: "\x1b[1;91;49m",
+		},
+		{
+			fg:       Color256(208),
+			bg:       ColorRGB(0, 0, 95),
+			depth:    ColorDepthTrueColor,
+			expected: "\x1b[1;38;5;208;48;2;0;0;95m",
+		},
+		{
+			fg:       Color256(208),
+			bg:       ColorRGB(0, 0, 95),
+			depth:    ColorDepth256,
+			expected: "\x1b[1;38;5;208;48;5;17m",
+		},
+		{
+			fg:       Color256(208),
+			bg:       ColorRGB(0, 0, 95),
+			depth:    ColorDepth16,
+			expected: "\x1b[1;33;40m",
+		},
+	}
+
+	for _, s := range scenarioTable {
+		pw := &VT100Writer{}
+		pw.SetColorDepth(s.depth)
+		pw.SetDisplayAttributes(s.fg, s.bg, DisplayBold)
+
+		if actual := string(pw.buffer); actual != s.expected {
+			t.Errorf("Should be %q, but got %q", s.expected, actual)
+		}
+	}
+}
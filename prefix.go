@@ -0,0 +1,74 @@
+package prompt
+
+import "strings"
+
+// PrefixSegment is a piece of the prefix drawn in its own style, such as a powerline segment.
+// A segment may contain line breaks: everything before the last one is drawn on lines above the input.
+type PrefixSegment struct {
+	Text  string
+	Style Style
+}
+
+// getCurrentPrefixSegments to get the segments of the current prefix.
+// Live prefixes take precedence over static ones, and a plain prefix is drawn as a single segment.
+func (r *Render) getCurrentPrefixSegments() []PrefixSegment {
+	if prefix, ok := r.livePrefixCallback(); ok {
+		return r.plainPrefix(prefix)
+	}
+	if r.livePrefixSegmentsCallback != nil {
+		if segments, ok := r.livePrefixSegmentsCallback(); ok {
+			return segments
+		}
+	}
+	if len(r.prefixSegments) > 0 {
+		return r.prefixSegments
+	}
+	return r.plainPrefix(r.prefix)
+}
+
+// plainPrefix draws prefix in the prefix style, or in the error style when the ExecutorContext failed
+// on the last input. The accepted line is drawn before the status is known, in the prefix style.
+func (r *Render) plainPrefix(prefix string) []PrefixSegment {
+	style := r.theme.Prefix
+	if r.status != 0 {
+		style = r.theme.Error
+	}
+	return []PrefixSegment{{
+		Text:  prefix,
+		Style: style,
+	}}
+}
+
+// splitPrefixLines splits segments at line breaks.
+// The last line is the one the input starts on.
+func splitPrefixLines(segments []PrefixSegment) [][]PrefixSegment {
+	lines := [][]PrefixSegment{nil}
+	for _, s := range segments {
+		for i, text := range strings.Split(s.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if text != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], PrefixSegment{Text: text, Style: s.Style})
+			}
+		}
+	}
+	return lines
+}
+
+// prefixText returns the text of segments as it appears on the terminal.
+// Escape characters are replaced the same way ConsoleWriter.WriteStr does, so that widths match what is drawn.
+func prefixText(segments []PrefixSegment) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteString(s.Text)
+	}
+	return strings.Replace(b.String(), "\x1b", "?", -1)
+}
+
+func drawPrefixSegments(s *screen, x, y int, segments []PrefixSegment) (int, int) {
+	for _, segment := range segments {
+		x, y = s.write(x, y, segment.Text, segment.Style)
+	}
+	return x, y
+}
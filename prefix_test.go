@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPrefixLines(t *testing.T) {
+	user := Style{TextColor: White, BGColor: DarkBlue}
+	dir := Style{TextColor: Black, BGColor: Green}
+
+	actual := splitPrefixLines([]PrefixSegment{
+		{Text: " user ", Style: user},
+		{Text: " ~/src \n", Style: dir},
+		{Text: "❯ "},
+	})
+	expected := [][]PrefixSegment{
+		{{Text: " user ", Style: user}, {Text: " ~/src ", Style: dir}},
+		{{Text: "❯ "}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestRenderPrefixSegments(t *testing.T) {
+	r := &Render{
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		prefixSegments: []PrefixSegment{
+			{Text: "a very long first line\n", Style: Style{TextColor: Red}},
+			{Text: "\x1b[31m$ "},
+		},
+		col: 10,
+	}
+
+	if prefix := r.getCurrentPrefix(); prefix != "?[31m$ " {
+		t.Errorf("Should be the last line of the prefix, but got %q", prefix)
+	}
+
+	sc := newScreen(10)
+	x, y := r.drawPrefix(sc)
+	expected := []string{"a very lon", "g first li", "ne", "?[31m$ "}
+	if actual := screenLines(sc); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+	if x != 7 || y != 3 {
+		t.Errorf("Should start the input at (7, 3), but got (%d, %d)", x, y)
+	}
+	if style := sc.rows[0][0].style; style != (Style{TextColor: Red}) {
+		t.Errorf("Should draw the first line in its style, but got %#v", style)
+	}
+}
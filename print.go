@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/aschey/go-prompt/internal/debug"
+)
+
+// Write prints b above the prompt while it's running, drawing the prompt again below it.
+// It's safe to call from any goroutine, so Prompt can be the io.Writer of loggers and the like.
+// When the prompt isn't shown, e.g. while the executor runs, b is written right away.
+func (p *Prompt) Write(b []byte) (int, error) {
+	p.printMu.Lock()
+	defer p.printMu.Unlock()
+	if !p.shown {
+		p.renderer.out.WriteRaw(b)
+		return len(b), p.renderer.flush()
+	}
+	p.printQueue = append(p.printQueue, b...)
+	select {
+	case p.printCh <- struct{}{}:
+	default:
+	}
+	return len(b), nil
+}
+
+// Printf formats according to a format specifier and prints the text above the prompt, like Write.
+func (p *Prompt) Printf(format string, a ...interface{}) (int, error) {
+	return fmt.Fprintf(p, format, a...)
+}
+
+// setShown sets whether the prompt is on the screen, printing what was queued in the meantime when it's hidden.
+// render is called with Write blocked, so that it doesn't race with the renderer.
+func (p *Prompt) setShown(shown bool, render func()) {
+	p.printMu.Lock()
+	defer p.printMu.Unlock()
+	if render != nil {
+		render()
+	}
+	p.shown = shown
+	if !shown && len(p.printQueue) > 0 {
+		p.renderer.out.WriteRaw(p.printQueue)
+		debug.AssertNoError(p.renderer.flush())
+		p.printQueue = nil
+	}
+}
+
+// drawHidden runs draw, which writes to the terminal while the prompt isn't shown, with Write blocked
+// so that they don't write at the same time.
+func (p *Prompt) drawHidden(draw func()) {
+	p.printMu.Lock()
+	defer p.printMu.Unlock()
+	draw()
+}
+
+// takePrinted returns the text queued by Write.
+func (p *Prompt) takePrinted() []byte {
+	p.printMu.Lock()
+	defer p.printMu.Unlock()
+	b := p.printQueue
+	p.printQueue = nil
+	return b
+}
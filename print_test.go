@@ -0,0 +1,101 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptWrite(t *testing.T) {
+	w := &bufferWriter{}
+	p := &Prompt{
+		buf: NewBuffer(),
+		renderer: &Render{
+			out:                w,
+			prefix:             "> ",
+			livePrefixCallback: func() (string, bool) { return "", false },
+			theme:              DefaultTheme(),
+			row:                20,
+			col:                40,
+		},
+		completion: NewCompletionManager(nil, 6),
+		printCh:    make(chan struct{}, 1),
+	}
+
+	if _, err := p.Printf("job %d started\n", 1); err != nil {
+		t.Fatal(err)
+	}
+	if out := string(w.buffer); out != "job 1 started\n" {
+		t.Errorf("Should write right away while the prompt isn't shown, but got %q", out)
+	}
+
+	p.buf.InsertText("select", false, true)
+	p.setShown(true, func() { p.renderer.Render(p.buf, p.completion) })
+	w.buffer = nil
+	p.Printf("job %d", 1)
+	p.Printf(" done")
+	if len(w.buffer) != 0 {
+		t.Errorf("Should queue the text while the prompt is shown, but got %q", w.buffer)
+	}
+	select {
+	case <-p.printCh:
+	default:
+		t.Fatal("Should signal the Run loop")
+	}
+
+	p.renderer.printAbove(p.takePrinted(), p.buf, p.completion)
+	out := string(w.buffer)
+	if !strings.HasPrefix(out, "\r\x1b[Jjob 1 done\n") {
+		t.Errorf("Should erase the prompt and print the text in its place, but got %q", out)
+	}
+	if !strings.Contains(out, "> \x1b[0;39;49mselect") {
+		t.Errorf("Should draw the prompt again below the text, but got %q", out)
+	}
+}
+
+func TestPromptWriteWhileExecuting(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		for _, s := range []string{"\x1b[1;1R", "a", "\r", "b", "\r", "c", "\r"} {
+			w.Write([]byte(s))
+		}
+		w.Close()
+	}()
+	var out bytes.Buffer
+	p := New(nil, func(d Document, results chan []Suggest) { results <- nil },
+		OptionParser(NewStreamParser(r)),
+		OptionWriter(NewStreamWriter(&out)),
+		OptionSignals(),
+		OptionExecutorContext(func(context.Context, string, *Suggest, []Suggest) error {
+			return errors.New("failed")
+		}),
+	)
+	done := make(chan struct{})
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				p.Printf("log\n")
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	p.Run()
+	close(done)
+	<-printed
+
+	if n := strings.Count(out.String(), "failed"); n != 3 {
+		t.Errorf("Want the 3 errors shown, but got %d in %q", n, out.String())
+	}
+}
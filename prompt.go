@@ -2,6 +2,10 @@ package prompt
 
 import (
 	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aschey/go-prompt/internal/debug"
@@ -26,6 +30,8 @@ type Prompt struct {
 	buf               *Buffer
 	renderer          *Render
 	executor          Executor
+	executorContext   ExecutorContext
+	errorHandler      ErrorHandler
 	history           *History
 	completion        *CompletionManager
 	keyBindings       []KeyBind
@@ -35,6 +41,49 @@ type Prompt struct {
 	exitChecker       ExitChecker
 	skipTearDown      bool
 	statusbarChan     chan string
+	themeChan         chan Theme
+	jobControl        bool
+	suspended         bool
+	signals           []os.Signal
+	interruptHandler  InterruptHandler
+	terminateHandler  SignalHandler
+	quitHandler       SignalHandler
+	// exitCode is the code Run returns when feed says to exit.
+	exitCode int
+	// script is read line by line instead of the terminal when it's set.
+	script io.Reader
+	// interactive keeps reading the terminal when the standard input isn't one.
+	interactive bool
+	// recorder records the session when it's set.
+	recorder *Recorder
+	// controlCh holds the changes made from other goroutines, for the Run loop to apply.
+	controlCh chan func(*controlResult)
+	// exitCh holds the code of the first Exit call Run didn't get to.
+	exitCh chan int
+	// keySequenceBindings are bound in keymap when Run starts, after the key sequences of the key bind mode.
+	keySequenceBindings []KeySequenceBind
+	keySequenceTimeout  time.Duration
+	keymap              *keymap
+	// pendingKeymap is where the keys read so far, pendingKeys, lead in keymap, while they start a sequence.
+	pendingKeymap   *keymap
+	pendingKeys     [][]byte
+	pendingDeadline time.Time
+	// undoStack holds the input as it was before each change, for Ctrl+x Ctrl+u.
+	undoStack []Document
+	// editor edits the input for Ctrl+x Ctrl+e, which sets editRequested for Run to call it.
+	// Ctrl+x Ctrl+e is unbound without one.
+	editor        func(string) (string, error)
+	editorSet     bool
+	editRequested bool
+	// idleHandler is called with the number of inputs read, reads, whenever the Run loop handled them all.
+	idleHandler func(reads int)
+	reads       uint32
+
+	// printMu guards the text printed above the prompt, and whether the prompt is on the screen.
+	printMu    sync.Mutex
+	printQueue []byte
+	printCh    chan struct{}
+	shown      bool
 }
 
 // Exec is the struct contains user input context.
@@ -44,31 +93,72 @@ type Exec struct {
 
 // Run starts prompt.
 func (p *Prompt) Run() int {
+	defer p.discardControls()
 	p.skipTearDown = false
+	p.exitCode = 0
+	if p.script != nil {
+		return p.runNonInteractive(p.script)
+	}
 	defer debug.Teardown()
 	debug.Log("start prompt")
+	atomic.StoreUint32(&p.reads, 0)
 	p.setUp()
 	defer p.tearDown()
+	typeahead := p.locateCursor()
 
 	if p.completion.showAtStart {
 		p.completion.Update(*p.buf.Document())
 	}
 
-	p.renderer.Render(p.buf, p.completion)
+	p.keymap = p.buildKeymap()
+	p.resetKeySequence()
+	p.renderer.setReading(true)
+	p.setShown(true, func() { p.renderer.Render(p.buf, p.completion) })
 
 	bufCh := make(chan []byte, 128)
+	if len(typeahead) > 0 {
+		bufCh <- typeahead
+	}
+	// The goroutines are only told to stop while they run, as nothing receives otherwise.
 	stopReadBufCh := make(chan struct{})
-	go p.readBuffer(bufCh, stopReadBufCh)
+	readingBuf := false
+	startReadBuf := func() {
+		go p.readBuffer(bufCh, stopReadBufCh)
+		readingBuf = true
+	}
+	stopReadBuf := func() {
+		if readingBuf {
+			stopReadBufCh <- struct{}{}
+			readingBuf = false
+		}
+	}
+	startReadBuf()
 
-	exitCh := make(chan int)
+	signalCh := make(chan os.Signal)
 	winSizeCh := make(chan *WinSize)
+	resumeCh := make(chan struct{})
 	stopHandleSignalCh := make(chan struct{})
-	go p.handleSignals(exitCh, winSizeCh, stopHandleSignalCh)
+	handlingSignals := false
+	startHandleSignals := func() {
+		go p.handleSignals(signalCh, winSizeCh, resumeCh, stopHandleSignalCh)
+		handlingSignals = true
+	}
+	stopHandleSignals := func() {
+		if handlingSignals {
+			stopHandleSignalCh <- struct{}{}
+			handlingSignals = false
+		}
+	}
+	startHandleSignals()
+	var resizedCh <-chan struct{}
+	if n, ok := p.in.(resizeNotifier); ok {
+		resizedCh = n.Resized()
+	}
 
 	defer func() {
-		p.renderer.BreakLine(p.buf)
-		stopReadBufCh <- struct{}{}
-		stopHandleSignalCh <- struct{}{}
+		p.setShown(false, func() { p.renderer.BreakLine(p.buf) })
+		stopReadBuf()
+		stopHandleSignals()
 	}()
 
 	updating := false
@@ -86,33 +176,43 @@ func (p *Prompt) Run() int {
 	}
 
 	var lastChosen *Suggest = nil
-	for {
-		select {
-		case b := <-bufCh:
+	// handleKeys handles the inputs as single keys, and draws the prompt again.
+	// An empty keys means that a key sequence was started or run instead.
+	handleKeys := func(keys [][]byte) (exit bool, code int) {
+		for _, b := range keys {
 			if shouldExit, e := p.feed(b); shouldExit {
-				return 0
+				return true, p.exitCode
 			} else if e != nil {
 				// Stop goroutine to run readBuffer function
-				stopReadBufCh <- struct{}{}
-				stopHandleSignalCh <- struct{}{}
+				stopReadBuf()
+				stopHandleSignals()
 				// Unset raw mode
 				// Reset to Blocking mode because returned EAGAIN when still set non-blocking mode.
 				debug.AssertNoError(p.in.TearDown())
+				p.renderer.setReading(false)
+				p.renderer.reportMouse(false)
 
-				p.executor(e.input, lastChosen, p.completion.tmp)
+				p.setShown(false, nil)
+				exit, code := p.execute(e.input, lastChosen, p.completion.GetSuggestions())
 
 				requestPromptUpdate()
 
-				p.renderer.Render(p.buf, p.completion)
+				p.setShown(true, func() { p.renderer.Render(p.buf, p.completion) })
 
+				if exit {
+					p.skipTearDown = true
+					return true, code
+				}
 				if p.exitChecker != nil && p.exitChecker(e.input, true) {
 					p.skipTearDown = true
-					return 0
+					return true, 0
 				}
 				// Set raw mode
 				debug.AssertNoError(p.in.Setup())
-				go p.readBuffer(bufCh, stopReadBufCh)
-				go p.handleSignals(exitCh, winSizeCh, stopHandleSignalCh)
+				p.renderer.reportMouse(true)
+				p.renderer.setReading(true)
+				startReadBuf()
+				startHandleSignals()
 			} else {
 				requestPromptUpdate()
 				if p.completion.selected > -1 && p.completion.selected < len(p.completion.tmp) {
@@ -122,12 +222,60 @@ func (p *Prompt) Run() int {
 				}
 				p.renderer.Render(p.buf, p.completion)
 			}
+		}
+		if p.editRequested {
+			p.editRequested = false
+			// The editor reads the terminal.
+			stopReadBuf()
+			p.editInEditor()
+			startReadBuf()
+		}
+		if len(keys) == 0 {
+			requestPromptUpdate()
+			p.renderer.Render(p.buf, p.completion)
+		}
+		return false, 0
+	}
+
+	for {
+		select {
+		case b := <-bufCh:
+			if b == nil {
+				// The input was closed, like a connection that ended.
+				return p.exitCode
+			}
+			if p.jobControl && jobControlSupported && GetKey(b) == ControlZ {
+				// Stop reading while the process is stopped, as the terminal isn't in raw mode.
+				stopReadBuf()
+				p.resetKeySequence()
+				p.suspend()
+				continue
+			}
+			// The terminal may answer a cursor position request in the middle of the keys.
+			if b = p.takeCursorPositionReport(b); len(b) == 0 {
+				continue
+			}
+			if exit, code := handleKeys(p.matchKeySequence(b)); exit {
+				return code
+			}
+		case <-resumeCh:
+			if p.resume() {
+				startReadBuf()
+			}
+			p.renderer.Render(p.buf, p.completion)
 		case w := <-winSizeCh:
 			p.renderer.UpdateWinSize(w)
 			requestPromptUpdate()
 			p.renderer.Render(p.buf, p.completion)
-		case code := <-exitCh:
-			return code
+		case <-resizedCh:
+			p.renderer.UpdateWinSize(p.in.GetWinSize())
+			requestPromptUpdate()
+			p.renderer.Render(p.buf, p.completion)
+		case sig := <-signalCh:
+			if exit, code := p.handleSignal(sig); exit {
+				return code
+			}
+			p.renderer.Render(p.buf, p.completion)
 		case results := <-resultsCh:
 			updating = false
 			p.completion.SetResults(results)
@@ -139,7 +287,30 @@ func (p *Prompt) Run() int {
 		case statusBar := <-p.statusbarChan:
 			p.renderer.statusBar = statusBar
 			p.renderer.Render(p.buf, p.completion)
+		case theme := <-p.themeChan:
+			p.renderer.theme = theme
+			p.renderer.Render(p.buf, p.completion)
+		case <-p.printCh:
+			p.renderer.printAbove(p.takePrinted(), p.buf, p.completion)
+		case code := <-p.exitCh:
+			p.applyControls()
+			return code
+		case fn := <-p.controlCh:
+			var c controlResult
+			fn(&c)
+			if c.update {
+				requestPromptUpdate()
+			}
+			p.renderer.Render(p.buf, p.completion)
 		default:
+			if p.keySequenceExpired() {
+				if exit, code := handleKeys(p.expireKeySequence()); exit {
+					return code
+				}
+			}
+			if p.idleHandler != nil && !updating && !pending && len(bufCh) == 0 {
+				p.idleHandler(int(atomic.LoadUint32(&p.reads)))
+			}
 			time.Sleep(10 * time.Millisecond)
 		}
 	}
@@ -147,6 +318,8 @@ func (p *Prompt) Run() int {
 
 func (p *Prompt) feed(b []byte) (shouldExit bool, exec *Exec) {
 	key := GetKey(b)
+	buf, before := p.buf, *p.buf.Document()
+	defer func() { p.recordUndo(buf, before) }()
 	p.buf.lastKeyStroke = key
 	// completion
 	completing := p.completion.Completing()
@@ -155,6 +328,8 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, exec *Exec) {
 	case Enter, ControlJ, ControlM:
 		p.handleCompletionKeyBinding(key, completing)
 		p.renderer.BreakLine(p.buf)
+		// The accepted line keeps the status of the previous input, which is reset until this one is executed.
+		p.renderer.status = 0
 
 		exec = &Exec{input: p.buf.Text()}
 		p.buf = NewBuffer()
@@ -162,10 +337,11 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, exec *Exec) {
 			p.history.Add(exec.input)
 		}
 	case ControlC:
-		p.handleCompletionKeyBinding(key, completing)
-		p.renderer.BreakLine(p.buf)
-		p.buf = NewBuffer()
-		p.history.Clear()
+		if exit, code := p.interrupt(false); exit {
+			p.exitCode = code
+			shouldExit = true
+			return
+		}
 	case Up, ControlP:
 		if !completing { // Don't use p.completion.Completing() because it takes double operation when switch to selected=-1.
 			if newBuf, changed := p.history.Older(p.buf); changed {
@@ -192,6 +368,8 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, exec *Exec) {
 			shouldExit = true
 			return
 		}
+	case Vt100MouseEvent:
+		p.handleMouse(b)
 	case NotDefined:
 		p.handleCompletionKeyBinding(key, completing)
 		if p.handleASCIICodeBinding(b) {
@@ -221,16 +399,38 @@ func (p *Prompt) handleCompletionKeyBinding(key Key, completing bool) {
 		}
 	case BackTab:
 		p.completion.Previous()
+	case PageDown:
+		if completing {
+			p.completion.NextGroup()
+		}
+	case PageUp:
+		if completing {
+			p.completion.PreviousGroup()
+		}
+	case ShiftDown:
+		if completing {
+			p.completion.ScrollDocumentationDown()
+		}
+	case ShiftUp:
+		if completing {
+			p.completion.ScrollDocumentationUp()
+		}
 	default:
-		if s, ok := p.completion.GetSelectedSuggestion(); ok {
-			w := p.buf.Document().GetWordBeforeCursorUntilSeparator(p.completion.wordSeparator)
-			if w != "" {
-				p.buf.DeleteBeforeCursor(len([]rune(w)))
-			}
-			p.buf.InsertText(s.Text, true, true)
+		p.acceptCompletion()
+	}
+}
+
+// acceptCompletion replaces the word before the cursor with the selected suggestion, if any,
+// and resets the selection.
+func (p *Prompt) acceptCompletion() {
+	if s, ok := p.completion.GetSelectedSuggestion(); ok {
+		w := p.buf.Document().GetWordBeforeCursorUntilSeparator(p.completion.wordSeparator)
+		if w != "" {
+			p.buf.DeleteBeforeCursor(len([]rune(w)))
 		}
-		p.completion.Reset()
+		p.buf.InsertText(s.Text, true, true)
 	}
+	p.completion.Reset()
 }
 
 func (p *Prompt) handleKeyBinding(key Key) bool {
@@ -249,6 +449,9 @@ func (p *Prompt) handleKeyBinding(key Key) bool {
 				kb.Fn(p.buf)
 			}
 		}
+		if key == ControlL {
+			p.renderer.clearScreen()
+		}
 	}
 
 	// Custom key bindings
@@ -277,6 +480,7 @@ func (p *Prompt) handleASCIICodeBinding(b []byte) bool {
 
 func (p *Prompt) readBuffer(bufCh chan []byte, stopCh chan struct{}) {
 	debug.Log("start reading buffer")
+	closed := false
 	for {
 		select {
 		case <-stopCh:
@@ -285,6 +489,11 @@ func (p *Prompt) readBuffer(bufCh chan []byte, stopCh chan struct{}) {
 		default:
 			if b, err := p.in.Read(); err == nil && !(len(b) == 1 && b[0] == 0) {
 				bufCh <- b
+				// Counted once sent, so that the Run loop isn't idle with inputs read but not handled.
+				atomic.AddUint32(&p.reads, 1)
+			} else if err == io.EOF && !closed {
+				closed = true
+				bufCh <- nil
 			}
 		}
 		time.Sleep(10 * time.Millisecond)
@@ -293,7 +502,7 @@ func (p *Prompt) readBuffer(bufCh chan []byte, stopCh chan struct{}) {
 
 func (p *Prompt) setUp() {
 	debug.AssertNoError(p.in.Setup())
-	p.renderer.Setup()
+	p.drawHidden(p.renderer.Setup)
 	p.renderer.UpdateWinSize(p.in.GetWinSize())
 }
 
@@ -301,5 +510,5 @@ func (p *Prompt) tearDown() {
 	if !p.skipTearDown {
 		debug.AssertNoError(p.in.TearDown())
 	}
-	p.renderer.TearDown()
+	p.drawHidden(p.renderer.TearDown)
 }
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestPromptsRunConcurrently is meant for the race detector: go test -race.
+func TestPromptsRunConcurrently(t *testing.T) {
+	const n = 8
+	var wg sync.WaitGroup
+	outputs := make([]bytes.Buffer, n)
+	executed := make([]string, n)
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, w := io.Pipe()
+			go func() {
+				keys := []string{"\x1b[1;1R", "select", "\x0c", " 1;", "\r"}
+				if i%2 == 0 {
+					// Only some of the prompts clear their screen.
+					keys = []string{"\x1b[1;1R", "select", " 1;", "\r"}
+				}
+				for _, key := range keys {
+					w.Write([]byte(key))
+				}
+				w.Close()
+			}()
+			p := New(func(in string, _ *Suggest, _ []Suggest) {
+				executed[i] = in
+			}, func(d Document, results chan []Suggest) {
+				results <- []Suggest{{Text: "select"}, {Text: "from"}}
+			},
+				OptionParser(NewStreamParser(r)),
+				OptionWriter(NewStreamWriter(&outputs[i])),
+				OptionSignals(),
+				OptionTitle("prompt"),
+			)
+			p.Run()
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if executed[i] != "select 1;" {
+			t.Errorf("[prompt %d] Want %q executed, but got %q", i, "select 1;", executed[i])
+		}
+		cleared := strings.Contains(outputs[i].String(), "\x1b[2J")
+		if cleared != (i%2 == 1) {
+			t.Errorf("[prompt %d] Only the prompts which got Ctrl+l should clear their screen, but got %t", i, cleared)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package prompttest
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	prompt "github.com/aschey/go-prompt"
+)
+
+// errNoInput is returned by Parser.Read when no key is waiting.
+var errNoInput = errors.New("no input available")
+
+// Parser is a ConsoleParser that reads the keys fed into it. It's safe for concurrent use.
+type Parser struct {
+	mu      sync.Mutex
+	keys    [][]byte
+	closed  bool
+	winSize prompt.WinSize
+	resized chan struct{}
+	// reads is the number of keys read.
+	reads int
+}
+
+// NewParser returns a Parser for a window of cols columns and rows rows.
+func NewParser(cols, rows int) *Parser {
+	return &Parser{
+		winSize: prompt.WinSize{Col: uint16(cols), Row: uint16(rows)},
+		resized: make(chan struct{}, 1),
+	}
+}
+
+// Feed queues b, which the prompt reads as one chunk, like a key or a paste.
+func (p *Parser) Feed(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, append([]byte{}, b...))
+}
+
+// answer queues the answer of the terminal to a query after the keys already waiting.
+// It's read along with the last one of them, as the bytes of both would be in the input queue of a terminal.
+func (p *Parser) answer(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.keys); n > 0 {
+		p.keys[n-1] = append(p.keys[n-1], b...)
+	} else {
+		p.keys = append(p.keys, append([]byte{}, b...))
+	}
+}
+
+// Close ends the input once the keys waiting are read. Run returns then.
+func (p *Parser) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+// handled tells whether every key fed was read, and reads is the number of them.
+func (p *Parser) handled(reads int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys) == 0 && p.reads == reads
+}
+
+// Setup should be called before starting input
+func (p *Parser) Setup() error {
+	return nil
+}
+
+// TearDown should be called after stopping input
+func (p *Parser) TearDown() error {
+	return nil
+}
+
+// Read returns byte array.
+func (p *Parser) Read() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		if p.closed {
+			return nil, io.EOF
+		}
+		return nil, errNoInput
+	}
+	b := p.keys[0]
+	p.keys = p.keys[1:]
+	p.reads++
+	return b, nil
+}
+
+// GetWinSize returns WinSize object to represent width and height of terminal.
+func (p *Parser) GetWinSize() *prompt.WinSize {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ws := p.winSize
+	return &ws
+}
+
+// SetWinSize changes the window size, and has the prompt drawn again to fit it.
+func (p *Parser) SetWinSize(ws prompt.WinSize) {
+	p.mu.Lock()
+	p.winSize = ws
+	p.mu.Unlock()
+	select {
+	case p.resized <- struct{}{}:
+	default:
+	}
+}
+
+// Resized is signaled when the window size changes.
+func (p *Parser) Resized() <-chan struct{} {
+	return p.resized
+}
+
+var _ prompt.ConsoleParser = &Parser{}
@@ -0,0 +1,499 @@
+package prompttest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	prompt "github.com/aschey/go-prompt"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// cell is a character on the screen, with the style it was written in.
+// The right half of a wide character is an empty continuation cell.
+type cell struct {
+	text         string
+	style        prompt.Style
+	continuation bool
+}
+
+// Screen is a small VT100 emulator, with the escape sequences go-prompt writes.
+// It keeps the colors and display attributes of every cell. It's an io.Writer, and is safe for concurrent use.
+type Screen struct {
+	mu            sync.Mutex
+	cols, rows    int
+	grid          [][]cell
+	main          [][]cell
+	alternate     bool
+	x, y          int
+	savedX        int
+	savedY        int
+	top, bottom   int
+	cursorVisible bool
+	title         string
+	// pen is the style characters are written in.
+	pen prompt.Style
+	// pending is the end of the last write, when it stopped in the middle of an escape sequence or a rune.
+	pending []byte
+	// onCPR is called when a cursor position report is asked for, with the position counted from 1.
+	onCPR func(row, col int)
+}
+
+// NewScreen returns a blank screen of cols columns and rows rows, with the cursor at its top left corner.
+func NewScreen(cols, rows int) *Screen {
+	s := &Screen{cols: cols, rows: rows, cursorVisible: true}
+	s.grid = blank(cols, rows)
+	s.bottom = rows - 1
+	return s
+}
+
+func blank(cols, rows int) [][]cell {
+	grid := make([][]cell, rows)
+	for y := range grid {
+		grid[y] = blankRow(cols)
+	}
+	return grid
+}
+
+func blankRow(cols int) []cell {
+	row := make([]cell, cols)
+	for x := range row {
+		row[x] = cell{text: " "}
+	}
+	return row
+}
+
+// Lines returns the rows of the screen, without the spaces they end with.
+func (s *Screen) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := make([]string, len(s.grid))
+	for y, row := range s.grid {
+		var b strings.Builder
+		for _, c := range row {
+			if !c.continuation {
+				b.WriteString(c.text)
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// String returns the lines of the screen, without the empty lines it ends with.
+func (s *Screen) String() string {
+	return strings.TrimRight(strings.Join(s.Lines(), "\n"), "\n")
+}
+
+// StyleAt returns the style of the character at row and col, counted from 0, as the prompt wrote it:
+// colors are degraded to the color depth of the writer. Blank cells have the zero Style.
+func (s *Screen) StyleAt(row, col int) prompt.Style {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if row < 0 || row >= s.rows || col < 0 || col >= s.cols {
+		return prompt.Style{}
+	}
+	return s.grid[row][col].style
+}
+
+// Cursor returns the row and the column of the cursor, counted from 0.
+func (s *Screen) Cursor() (row, col int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := s.x
+	if x >= s.cols {
+		x = s.cols - 1
+	}
+	return s.y, x
+}
+
+// CursorVisible tells whether the cursor is shown.
+func (s *Screen) CursorVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursorVisible
+}
+
+// Title returns the title of the window.
+func (s *Screen) Title() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.title
+}
+
+// Alternate tells whether the alternate screen is shown.
+func (s *Screen) Alternate() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alternate
+}
+
+// Resize changes the size of the screen, keeping the text in its top left corner.
+func (s *Screen) Resize(cols, rows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resize := func(grid [][]cell) [][]cell {
+		g := blank(cols, rows)
+		for y := 0; y < rows && y < len(grid); y++ {
+			copy(g[y], grid[y])
+		}
+		return g
+	}
+	s.grid = resize(s.grid)
+	if s.main != nil {
+		s.main = resize(s.main)
+	}
+	s.cols, s.rows = cols, rows
+	s.top, s.bottom = 0, rows-1
+	s.x, s.y = clamp(s.x, 0, cols-1), clamp(s.y, 0, rows-1)
+}
+
+// Write interprets p.
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := append(s.pending, p...)
+	s.pending = nil
+	for len(b) > 0 {
+		n := s.interpret(b)
+		if n == 0 {
+			s.pending = append([]byte{}, b...)
+			break
+		}
+		b = b[n:]
+	}
+	return len(p), nil
+}
+
+// interpret handles the control sequence or the rune b starts with, and returns its length,
+// or 0 when b holds only its beginning.
+func (s *Screen) interpret(b []byte) int {
+	switch b[0] {
+	case 0x1b:
+		return s.escape(b)
+	case '\r':
+		s.x = 0
+	case '\n':
+		s.lineFeed()
+	case '\b':
+		if s.x >= s.cols {
+			s.x = s.cols - 1
+		}
+		if s.x > 0 {
+			s.x--
+		}
+	case '\t':
+		s.x = (s.x/8 + 1) * 8
+		if s.x >= s.cols {
+			s.x = s.cols - 1
+		}
+	default:
+		if b[0] < ' ' || b[0] == 0x7f {
+			return 1
+		}
+		if !utf8.FullRune(b) {
+			return 0
+		}
+		r, n := utf8.DecodeRune(b)
+		s.print(r)
+		return n
+	}
+	return 1
+}
+
+// print writes r at the cursor. Like terminals do, the cursor stays past the last column after it's filled,
+// and the row wraps when the next character is written.
+func (s *Screen) print(r rune) {
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		// Combining characters join the character before them.
+		x := s.x - 1
+		for x > 0 && x < s.cols && s.grid[s.y][x].continuation {
+			x--
+		}
+		if x >= 0 && x < s.cols {
+			s.grid[s.y][x].text += string(r)
+		}
+		return
+	}
+	if s.x+w > s.cols {
+		s.x = 0
+		s.lineFeed()
+	}
+	s.grid[s.y][s.x] = cell{text: string(r), style: s.pen}
+	if w == 2 {
+		s.grid[s.y][s.x+1] = cell{style: s.pen, continuation: true}
+	}
+	s.x += w
+}
+
+// lineFeed moves the cursor down, scrolling the region at its bottom.
+func (s *Screen) lineFeed() {
+	if s.y == s.bottom {
+		s.scrollUp()
+	} else if s.y < s.rows-1 {
+		s.y++
+	}
+}
+
+func (s *Screen) reverseLineFeed() {
+	if s.y == s.top {
+		s.scrollDown()
+	} else if s.y > 0 {
+		s.y--
+	}
+}
+
+func (s *Screen) scrollUp() {
+	copy(s.grid[s.top:s.bottom], s.grid[s.top+1:s.bottom+1])
+	s.grid[s.bottom] = blankRow(s.cols)
+}
+
+func (s *Screen) scrollDown() {
+	copy(s.grid[s.top+1:s.bottom+1], s.grid[s.top:s.bottom])
+	s.grid[s.top] = blankRow(s.cols)
+}
+
+// escape handles the escape sequence b starts with, and returns its length, or 0 when it's incomplete.
+func (s *Screen) escape(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	switch b[1] {
+	case '[':
+		return s.csi(b)
+	case ']':
+		return s.osc(b)
+	case 'D':
+		s.lineFeed()
+	case 'M':
+		s.reverseLineFeed()
+	case '7':
+		s.savedX, s.savedY = s.x, s.y
+	case '8':
+		s.x, s.y = s.savedX, s.savedY
+	}
+	return 2
+}
+
+// csi handles ESC [ followed by parameters and a final byte.
+func (s *Screen) csi(b []byte) int {
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		end++
+	}
+	if end == len(b) {
+		return 0
+	}
+	params := string(b[2:end])
+	private := strings.HasPrefix(params, "?")
+	params = strings.TrimPrefix(params, "?")
+	var args []int
+	if params != "" {
+		for _, p := range strings.Split(params, ";") {
+			n, _ := strconv.Atoi(p)
+			args = append(args, n)
+		}
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	if s.x >= s.cols && strings.IndexByte("ABCDGHfJKs", b[end]) >= 0 {
+		// Moving or erasing cancels the wrap of a full row.
+		s.x = s.cols - 1
+	}
+	switch b[end] {
+	case 'A':
+		s.y = clamp(s.y-arg(0, 1), 0, s.rows-1)
+	case 'B':
+		s.y = clamp(s.y+arg(0, 1), 0, s.rows-1)
+	case 'C':
+		s.x = clamp(s.x+arg(0, 1), 0, s.cols-1)
+	case 'D':
+		s.x = clamp(s.x-arg(0, 1), 0, s.cols-1)
+	case 'G':
+		s.x = clamp(arg(0, 1)-1, 0, s.cols-1)
+	case 'H', 'f':
+		s.y = clamp(arg(0, 1)-1, 0, s.rows-1)
+		s.x = clamp(arg(1, 1)-1, 0, s.cols-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'm':
+		if !private {
+			s.setGraphicRendition(args)
+		}
+	case 'n':
+		if arg(0, 0) == 6 && s.onCPR != nil {
+			s.onCPR(s.y+1, s.x+1)
+		}
+	case 'r':
+		s.top = clamp(arg(0, 1)-1, 0, s.rows-1)
+		s.bottom = clamp(arg(1, s.rows)-1, s.top, s.rows-1)
+		s.x, s.y = 0, 0
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.x, s.y = s.savedX, s.savedY
+	case 'h', 'l':
+		if private {
+			s.setMode(arg(0, 0), b[end] == 'h')
+		}
+	}
+	return end + 1
+}
+
+// setGraphicRendition changes the pen with the parameters of SGR, ESC [ ... m.
+func (s *Screen) setGraphicRendition(args []int) {
+	if len(args) == 0 {
+		args = []int{0}
+	}
+	for i := 0; i < len(args); i++ {
+		switch n := args[i]; {
+		case n == 0:
+			s.pen = prompt.Style{}
+		case n == 1:
+			s.pen.Bold = true
+		case n == 2:
+			s.pen.Dim = true
+		case n == 3:
+			s.pen.Italic = true
+		case n == 4:
+			s.pen.Underline = true
+		case n == 7:
+			s.pen.Reverse = true
+		case n == 9:
+			s.pen.CrossedOut = true
+		case n == 22:
+			s.pen.Bold, s.pen.Dim = false, false
+		case n == 23:
+			s.pen.Italic = false
+		case n == 24:
+			s.pen.Underline = false
+		case n == 27:
+			s.pen.Reverse = false
+		case n == 29:
+			s.pen.CrossedOut = false
+		case n >= 30 && n <= 37:
+			s.pen.TextColor = prompt.Black + prompt.Color(n-30)
+		case n >= 90 && n <= 97:
+			s.pen.TextColor = prompt.Black + prompt.Color(n-90+8)
+		case n == 39:
+			s.pen.TextColor = prompt.DefaultColor
+		case n >= 40 && n <= 47:
+			s.pen.BGColor = prompt.Black + prompt.Color(n-40)
+		case n >= 100 && n <= 107:
+			s.pen.BGColor = prompt.Black + prompt.Color(n-100+8)
+		case n == 49:
+			s.pen.BGColor = prompt.DefaultColor
+		case n == 38 || n == 48:
+			c, used := extendedColor(args[i+1:])
+			if n == 38 {
+				s.pen.TextColor = c
+			} else {
+				s.pen.BGColor = c
+			}
+			i += used
+		}
+	}
+}
+
+// extendedColor decodes the parameters following 38 or 48: 5;n for the 256-color palette,
+// or 2;r;g;b for true colors. It returns the color, and how many parameters it took.
+func extendedColor(args []int) (prompt.Color, int) {
+	switch {
+	case len(args) >= 2 && args[0] == 5:
+		return prompt.Color256(uint8(args[1])), 2
+	case len(args) >= 4 && args[0] == 2:
+		return prompt.ColorRGB(uint8(args[1]), uint8(args[2]), uint8(args[3])), 4
+	}
+	return prompt.DefaultColor, len(args)
+}
+
+func (s *Screen) setMode(mode int, on bool) {
+	switch mode {
+	case 25:
+		s.cursorVisible = on
+	case 1049:
+		if on == s.alternate {
+			return
+		}
+		s.alternate = on
+		if on {
+			s.main = s.grid
+			s.savedX, s.savedY = s.x, s.y
+			s.grid = blank(s.cols, s.rows)
+		} else {
+			s.grid = s.main
+			s.main = nil
+			s.x, s.y = s.savedX, s.savedY
+		}
+	}
+}
+
+// osc handles ESC ] followed by text ended with BEL or ESC \.
+func (s *Screen) osc(b []byte) int {
+	for i := 2; i < len(b); i++ {
+		switch {
+		case b[i] == 0x07:
+			s.command(string(b[2:i]))
+			return i + 1
+		case b[i] == 0x1b && i+1 < len(b) && b[i+1] == '\\':
+			s.command(string(b[2:i]))
+			return i + 2
+		}
+	}
+	return 0
+}
+
+func (s *Screen) command(c string) {
+	if strings.HasPrefix(c, "0;") || strings.HasPrefix(c, "2;") {
+		s.title = c[2:]
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.y + 1; y < s.rows; y++ {
+			s.grid[y] = blankRow(s.cols)
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.y; y++ {
+			s.grid[y] = blankRow(s.cols)
+		}
+	case 2:
+		s.grid = blank(s.cols, s.rows)
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := s.x, s.cols
+	switch mode {
+	case 1:
+		from, to = 0, s.x+1
+	case 2:
+		from = 0
+	}
+	for x := from; x < to && x < s.cols; x++ {
+		s.grid[s.y][x] = cell{text: " "}
+	}
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
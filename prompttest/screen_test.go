@@ -0,0 +1,114 @@
+package prompttest
+
+import (
+	"reflect"
+	"testing"
+
+	prompt "github.com/aschey/go-prompt"
+)
+
+func TestScreen(t *testing.T) {
+	scenarioTable := []struct {
+		name     string
+		writes   []string
+		expected []string
+		row, col int
+	}{
+		{
+			name:     "wrap after the last column",
+			writes:   []string{"abcde", "f"},
+			expected: []string{"abcde", "f", ""},
+			row:      1, col: 1,
+		},
+		{
+			name:     "cursor stays on a full row",
+			writes:   []string{"abcde\r\n"},
+			expected: []string{"abcde", "", ""},
+			row:      1, col: 0,
+		},
+		{
+			name:     "wide characters",
+			writes:   []string{"日本語"},
+			expected: []string{"日本", "語", ""},
+			row:      1, col: 2,
+		},
+		{
+			name:     "sequences split across writes",
+			writes:   []string{"ab\x1b[", "1Dc\xe6\x97", "\xa5"},
+			expected: []string{"ac日", "", ""},
+			row:      0, col: 4,
+		},
+		{
+			name:     "erase",
+			writes:   []string{"abcde\r\nfghij\r\nklm", "\x1b[2;3H\x1b[K\x1b[1A\x1b[1K"},
+			expected: []string{"   de", "fg", "klm"},
+			row:      0, col: 2,
+		},
+		{
+			name:     "scroll",
+			writes:   []string{"a\r\nb\r\nc\r\nd"},
+			expected: []string{"b", "c", "d"},
+			row:      2, col: 1,
+		},
+		{
+			name:     "scroll region",
+			writes:   []string{"\x1b[3;3Hz\x1b[1;2r\x1b[2;1Ha\x1bDb\x1bDc"},
+			expected: []string{" b", "  c", "  z"},
+			row:      1, col: 3,
+		},
+	}
+
+	for _, s := range scenarioTable {
+		screen := NewScreen(5, 3)
+		for _, w := range s.writes {
+			screen.Write([]byte(w))
+		}
+		if lines := screen.Lines(); !reflect.DeepEqual(lines, s.expected) {
+			t.Errorf("[%s] Want %#v, but got %#v", s.name, s.expected, lines)
+		}
+		if row, col := screen.Cursor(); row != s.row || col != s.col {
+			t.Errorf("[%s] Want the cursor at %d, %d, but got %d, %d", s.name, s.row, s.col, row, col)
+		}
+	}
+}
+
+func TestScreenModes(t *testing.T) {
+	screen := NewScreen(10, 3)
+	var reported []int
+	screen.onCPR = func(row, col int) { reported = append(reported, row, col) }
+	screen.Write([]byte("main\x1b]2;title\x07\x1b[?25l\x1b[?1049h\x1b[2;3Halt\x1b[6n"))
+	if !screen.Alternate() || screen.CursorVisible() || screen.Title() != "title" {
+		t.Errorf("Should be on the alternate screen with a hidden cursor and a title, but got %t, %t, %q",
+			screen.Alternate(), screen.CursorVisible(), screen.Title())
+	}
+	if !reflect.DeepEqual(reported, []int{2, 6}) {
+		t.Errorf("Should report the cursor at 2, 6, but got %v", reported)
+	}
+	screen.Write([]byte("\x1b[?1049l\x1b[?25h"))
+	if s := screen.String(); s != "main" {
+		t.Errorf("Should restore the main screen, but got %q", s)
+	}
+	if row, col := screen.Cursor(); row != 0 || col != 4 {
+		t.Errorf("Should restore the cursor, but got %d, %d", row, col)
+	}
+}
+
+func TestScreenStyles(t *testing.T) {
+	screen := NewScreen(10, 3)
+	screen.Write([]byte("\x1b[0;1;91;49ma\x1b[38;5;24;48;2;1;2;3mb\x1b[7m日\x1b[mc\x1b[1;7mx\x1b[22md"))
+	expected := []prompt.Style{
+		{TextColor: prompt.Red, BGColor: prompt.DefaultColor, Bold: true},
+		{TextColor: prompt.Color256(24), BGColor: prompt.ColorRGB(1, 2, 3), Bold: true},
+		{TextColor: prompt.Color256(24), BGColor: prompt.ColorRGB(1, 2, 3), Bold: true, Reverse: true},
+		{TextColor: prompt.Color256(24), BGColor: prompt.ColorRGB(1, 2, 3), Bold: true, Reverse: true},
+		{},
+		{Bold: true, Reverse: true},
+		{Reverse: true},
+		{},
+	}
+	for col, style := range expected {
+		if actual := screen.StyleAt(0, col); actual != style {
+			t.Errorf("Want %#v at column %d, but got %#v", style, col, actual)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package prompttest runs prompts in a virtual terminal, to test completers and key bindings end to end.
+//
+// A Terminal feeds keys to the prompt one at a time, and waits for it to settle before the next one,
+// so that the screen can be checked in between:
+//
+//	term := prompttest.NewTerminal(40, 10)
+//	p := prompt.New(term.Executor(executor), completer, term.Options()...)
+//	term.Start(p)
+//	term.Type("sel")
+//	term.Press(prompt.Tab)
+//	if lines := term.Screen.Lines(); lines[0] != "> select" {
+//		t.Errorf("got %q", lines[0])
+//	}
+//	term.Press(prompt.Enter)
+//	if executed := term.Executed(); executed[0].Input != "select" {
+//		t.Errorf("got %q", executed[0].Input)
+//	}
+//	code := term.Close()
+package prompttest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	prompt "github.com/aschey/go-prompt"
+)
+
+// Terminal connects a Parser and a Screen like a terminal emulator does. It answers cursor position requests.
+// It runs a single prompt.
+type Terminal struct {
+	Parser *Parser
+	Screen *Screen
+
+	mu sync.Mutex
+	// reads is the number of keys the prompt handled when it was last idle, -1 before it was.
+	reads int
+	// idles is the number of times the prompt was idle.
+	idles int
+	// idle is signaled when the prompt is idle.
+	idle chan struct{}
+	done chan struct{}
+	code int
+	// executed are the calls of the executors returned by Executor and ExecutorContext.
+	executed []Execution
+}
+
+// Execution is a call of the executor.
+type Execution struct {
+	Input string
+	// Chosen is the suggestion selected when the input was accepted, if any.
+	Chosen *prompt.Suggest
+}
+
+// NewTerminal returns a Terminal of cols columns and rows rows.
+func NewTerminal(cols, rows int) *Terminal {
+	t := &Terminal{
+		Parser: NewParser(cols, rows),
+		Screen: NewScreen(cols, rows),
+		reads:  -1,
+		idle:   make(chan struct{}, 1),
+	}
+	t.Screen.onCPR = func(row, col int) {
+		t.Parser.answer([]byte("\x1b[" + strconv.Itoa(row) + ";" + strconv.Itoa(col) + "R"))
+	}
+	return t
+}
+
+// Options returns the options connecting a prompt to the terminal, and keeping it away from the signals
+// of the process.
+func (t *Terminal) Options() []prompt.Option {
+	return []prompt.Option{
+		prompt.OptionParser(t.Parser),
+		prompt.OptionWriter(prompt.NewStreamWriter(t.Screen)),
+		prompt.OptionSignals(),
+		prompt.OptionIdleHandler(t.onIdle),
+	}
+}
+
+// onIdle is called by the Run loop once it handled reads keys.
+func (t *Terminal) onIdle(reads int) {
+	t.mu.Lock()
+	t.reads = reads
+	t.idles++
+	t.mu.Unlock()
+	select {
+	case t.idle <- struct{}{}:
+	default:
+	}
+}
+
+// Executor returns an executor calling fn, which may be nil, and recording the call for Executed.
+func (t *Terminal) Executor(fn prompt.Executor) prompt.Executor {
+	return func(in string, chosen *prompt.Suggest, suggestions []prompt.Suggest) {
+		t.record(in, chosen)
+		if fn != nil {
+			fn(in, chosen, suggestions)
+		}
+	}
+}
+
+// ExecutorContext is Executor for prompt.OptionExecutorContext. A nil fn returns no error.
+func (t *Terminal) ExecutorContext(fn prompt.ExecutorContext) prompt.ExecutorContext {
+	return func(ctx context.Context, in string, chosen *prompt.Suggest, suggestions []prompt.Suggest) error {
+		t.record(in, chosen)
+		if fn == nil {
+			return nil
+		}
+		return fn(ctx, in, chosen, suggestions)
+	}
+}
+
+func (t *Terminal) record(in string, chosen *prompt.Suggest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if chosen != nil {
+		c := *chosen
+		chosen = &c
+	}
+	t.executed = append(t.executed, Execution{Input: in, Chosen: chosen})
+}
+
+// Executed returns the calls of the executor so far, in order.
+func (t *Terminal) Executed() []Execution {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Execution{}, t.executed...)
+}
+
+// Start runs p in the background, and waits for it to draw itself.
+func (t *Terminal) Start(p *prompt.Prompt) {
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		t.code = p.Run()
+	}()
+	t.Settle()
+}
+
+// Type feeds text as typed at once, and waits for the prompt to settle.
+func (t *Terminal) Type(text string) {
+	t.Parser.Feed([]byte(text))
+	t.Settle()
+}
+
+// Press feeds the keys one after the other, waiting for the prompt to settle after each of them.
+// It panics for keys that have no byte sequence.
+func (t *Terminal) Press(keys ...prompt.Key) {
+	for _, k := range keys {
+		t.Parser.Feed(KeySequence(k))
+		t.Settle()
+	}
+}
+
+// Resize changes the size of the screen, and tells the prompt.
+func (t *Terminal) Resize(cols, rows int) {
+	t.mu.Lock()
+	idles := t.idles
+	t.mu.Unlock()
+	t.Screen.Resize(cols, rows)
+	t.Parser.SetWinSize(prompt.WinSize{Col: uint16(cols), Row: uint16(rows)})
+	// The prompt may have been going idle already when the size changed, but it handles the change
+	// before it's idle again.
+	t.settle(idles + 2)
+}
+
+// Settle waits until the prompt handled every key fed into it and drew the result, or until Run returned.
+func (t *Terminal) Settle() {
+	t.settle(0)
+}
+
+// settle is Settle, once the prompt was idle at least idles times.
+func (t *Terminal) settle(idles int) {
+	for {
+		t.mu.Lock()
+		reads, n := t.reads, t.idles
+		t.mu.Unlock()
+		if reads >= 0 && n >= idles && t.Parser.handled(reads) {
+			return
+		}
+		select {
+		case <-t.done:
+			return
+		case <-t.idle:
+		}
+	}
+}
+
+// Close ends the input, and returns the code Run returned.
+func (t *Terminal) Close() int {
+	t.Parser.Close()
+	<-t.done
+	return t.code
+}
+
+// KeySequence returns the bytes a terminal sends for k. It panics for keys that have none.
+func KeySequence(k prompt.Key) []byte {
+	for _, s := range prompt.ASCIISequences {
+		if prompt.GetKey(s.ASCIICode) == k {
+			return s.ASCIICode
+		}
+	}
+	panic("prompttest: no byte sequence for " + k.String())
+}
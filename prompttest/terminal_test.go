@@ -0,0 +1,71 @@
+package prompttest
+
+import (
+	"reflect"
+	"testing"
+
+	prompt "github.com/aschey/go-prompt"
+)
+
+func TestTerminal(t *testing.T) {
+	term := NewTerminal(30, 8)
+	p := prompt.New(term.Executor(nil), func(d prompt.Document, results chan []prompt.Suggest) {
+		results <- prompt.FilterHasPrefix([]prompt.Suggest{
+			{Text: "select", Description: "Query rows"},
+			{Text: "set"},
+			{Text: "from"},
+		}, d.GetWordBeforeCursor(), true)
+	}, term.Options()...)
+	term.Start(p)
+
+	term.Type("se")
+	expected := []string{
+		"> se",
+		"     select  Query rows",
+		"     set",
+	}
+	if lines := term.Screen.Lines(); !reflect.DeepEqual(lines[:3], expected) {
+		t.Errorf("Want %#v, but got %#v", expected, lines)
+	}
+	if row, col := term.Screen.Cursor(); row != 0 || col != 4 {
+		t.Errorf("Want the cursor at 0, 4, but got %d, %d", row, col)
+	}
+
+	term.Press(prompt.Tab)
+	theme := prompt.DefaultTheme()
+	if style := term.Screen.StyleAt(1, 9); style != theme.SelectedSuggestion {
+		t.Errorf("Should select the first suggestion, but got %#v", style)
+	}
+	if style := term.Screen.StyleAt(2, 9); style != theme.Suggestion {
+		t.Errorf("Should leave the second suggestion unselected, but got %#v", style)
+	}
+
+	term.Press(prompt.Enter)
+	term.Type("from")
+	if lines := term.Screen.Lines(); lines[0] != "> select" || lines[1] != "> from" {
+		t.Errorf("Should accept the suggestion and draw the next prompt, but got %#v", lines)
+	}
+	if code := term.Close(); code != 0 {
+		t.Errorf("Want exit code 0, but got %d", code)
+	}
+	executed := term.Executed()
+	if len(executed) != 1 || executed[0].Input != "select" || executed[0].Chosen == nil || executed[0].Chosen.Text != "select" {
+		t.Errorf("Want %q executed with its suggestion chosen, but got %#v", "select", executed)
+	}
+}
+
+func TestTerminalResize(t *testing.T) {
+	term := NewTerminal(30, 8)
+	p := prompt.New(func(string, *prompt.Suggest, []prompt.Suggest) {}, func(_ prompt.Document, results chan []prompt.Suggest) {
+		results <- nil
+	}, term.Options()...)
+	term.Start(p)
+
+	term.Type("select")
+	term.Resize(6, 8)
+	expected := []string{"> sele", "ct"}
+	if lines := term.Screen.Lines(); !reflect.DeepEqual(lines[:2], expected) {
+		t.Errorf("Want %#v, but got %#v", expected, lines)
+	}
+	term.Close()
+}
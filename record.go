@@ -0,0 +1,185 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// castHeader is the first line of an asciicast v2 file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder records a session in the asciicast v2 format, the one of asciinema:
+// the output with its window size changes goes to one file, and the input read by the prompt goes to a sidecar
+// file of the same format, which ReplayParser reads back.
+type Recorder struct {
+	mu      sync.Mutex
+	output  io.Writer
+	input   io.Writer
+	now     func() time.Time
+	start   time.Time
+	started bool
+	winSize WinSize
+	err     error
+}
+
+// NewRecorder returns a Recorder writing the output to output, and the input to input.
+func NewRecorder(output, input io.Writer) *Recorder {
+	return &Recorder{output: output, input: input, now: time.Now}
+}
+
+// Err returns the first error writing the recording failed with.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// begin writes the headers, when the prompt sets up its input.
+func (r *Recorder) begin(ws *WinSize) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+	r.start = r.now()
+	r.winSize = *ws
+	h := castHeader{
+		Version:   2,
+		Width:     int(ws.Col),
+		Height:    int(ws.Row),
+		Timestamp: r.start.Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM")},
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.writeLine(r.output, b)
+	r.writeLine(r.input, b)
+}
+
+// event records data of the type code, "o" for output, "i" for input or "r" for a resize, in w.
+func (r *Recorder) event(w io.Writer, code, data string) {
+	t := r.now().Sub(r.start).Seconds()
+	b, err := json.Marshal([]interface{}{math.Round(t*1e6) / 1e6, code, data})
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.writeLine(w, b)
+}
+
+func (r *Recorder) writeLine(w io.Writer, b []byte) {
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		r.fail(err)
+	}
+}
+
+func (r *Recorder) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// recordInput records the input read by the prompt.
+func (r *Recorder) recordInput(b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		r.event(r.input, "i", string(b))
+	}
+}
+
+// recordWinSize records ws when it's a new window size, in both files.
+func (r *Recorder) recordWinSize(ws *WinSize) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started || *ws == r.winSize {
+		return
+	}
+	r.winSize = *ws
+	size := fmt.Sprintf("%dx%d", ws.Col, ws.Row)
+	r.event(r.output, "r", size)
+	r.event(r.input, "r", size)
+}
+
+// recordOutput records the output written to the terminal, with line feeds as the terminal driver sends them.
+func (r *Recorder) recordOutput(b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started && len(b) > 0 {
+		r.event(r.output, "o", string(bytes.Replace(b, []byte{'\n'}, []byte{'\r', '\n'}, -1)))
+	}
+}
+
+// recordingParser is a ConsoleParser recording what it reads.
+type recordingParser struct {
+	ConsoleParser
+	rec *Recorder
+}
+
+func (p *recordingParser) Setup() error {
+	if err := p.ConsoleParser.Setup(); err != nil {
+		return err
+	}
+	p.rec.begin(p.ConsoleParser.GetWinSize())
+	return nil
+}
+
+func (p *recordingParser) Read() ([]byte, error) {
+	b, err := p.ConsoleParser.Read()
+	if err == nil && len(b) > 0 && !(len(b) == 1 && b[0] == 0) {
+		// The cursor position reports answer the terminal of this session only, and aren't replayed.
+		in, _, _, _ := withoutCursorPositionReports(b)
+		if len(in) > 0 {
+			p.rec.recordInput(in)
+		}
+	}
+	return b, err
+}
+
+func (p *recordingParser) GetWinSize() *WinSize {
+	ws := p.ConsoleParser.GetWinSize()
+	p.rec.recordWinSize(ws)
+	return ws
+}
+
+// recordingResizeParser is a recordingParser for parsers that are told when the window size changes.
+type recordingResizeParser struct {
+	recordingParser
+	resizeNotifier
+}
+
+// bufferedWriter is a ConsoleWriter holding what it writes until it's flushed, like the ones built on VT100Writer.
+type bufferedWriter interface {
+	pending() []byte
+}
+
+func (w *VT100Writer) pending() []byte {
+	return w.buffer
+}
+
+// wrap has the parser of p record the input, and its renderer record what its writer flushes.
+func (r *Recorder) wrap(p *Prompt) {
+	rp := recordingParser{ConsoleParser: p.in, rec: r}
+	if n, ok := p.in.(resizeNotifier); ok {
+		p.in = &recordingResizeParser{recordingParser: rp, resizeNotifier: n}
+	} else {
+		p.in = &rp
+	}
+	p.renderer.recorder = r
+}
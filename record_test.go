@@ -0,0 +1,139 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var cast, input bytes.Buffer
+	rec := NewRecorder(&cast, &input)
+	var mu sync.Mutex
+	clock := time.Unix(1600000000, 0)
+	rec.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+
+	r, w := io.Pipe()
+	parser := NewStreamParser(r)
+	typed := make(chan struct{})
+	go func() {
+		w.Write([]byte("\x1b[1;1R"))
+		w.Write([]byte("select"))
+		<-typed
+		parser.SetWinSize(WinSize{Row: 30, Col: 100})
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("\r"))
+		w.Close()
+	}()
+	var executed []string
+	executor := func(in string, _ *Suggest, _ []Suggest) { executed = append(executed, in) }
+	var p *Prompt
+	resized := false
+	p = New(executor, func(_ Document, results chan []Suggest) { results <- nil },
+		OptionParser(parser),
+		OptionWriter(NewStreamWriter(ioutil.Discard)),
+		OptionSignals(),
+		OptionRecorder(rec),
+		OptionIdleHandler(func(int) {
+			if !resized && p.buf.Text() == "select" {
+				close(typed)
+				resized = true
+			}
+		}),
+	)
+	p.Run()
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	castLines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	var h castHeader
+	if err := json.Unmarshal([]byte(castLines[0]), &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != 2 || h.Width != 80 || h.Height != 25 || h.Timestamp != 1600000000 {
+		t.Errorf("Want a v2 header for 80x25, but got %#v", h)
+	}
+	if !strings.Contains(cast.String(), `"o","\u001b[?25lselect`) {
+		t.Errorf("Should record the output, but got %s", cast.String())
+	}
+	if !strings.Contains(cast.String(), `"r","100x30"]`) {
+		t.Errorf("Should record the window size change, but got %s", cast.String())
+	}
+
+	replay, err := NewReplayParser(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	replay.now = func() time.Time { return now }
+	replay.Setup()
+	if _, err := replay.Read(); err != errNoInput {
+		t.Errorf("Should wait for the time of the first input, but got %v", err)
+	}
+	now = now.Add(time.Hour)
+	var inputs []string
+	for {
+		b, err := replay.Read()
+		if err == errNoInput {
+			// The next input waits for the prompt to take in the window size change.
+			<-replay.Resized()
+			inputs = append(inputs, "resize")
+			continue
+		}
+		if err != nil {
+			break
+		}
+		inputs = append(inputs, string(b))
+	}
+	// The cursor position report isn't recorded.
+	expected := []string{"select", "resize", "\r"}
+	if !reflect.DeepEqual(inputs, expected) {
+		t.Errorf("Want %#v, but got %#v", expected, inputs)
+	}
+	if ws := replay.GetWinSize(); *ws != (WinSize{Row: 30, Col: 100}) {
+		t.Errorf("Should replay the window size change, but got %#v", ws)
+	}
+
+	input.Reset()
+	replay, _ = NewReplayParser(strings.NewReader(strings.SplitN(cast.String(), "\n", 2)[0] + "\n" +
+		`[0.01,"i","\u001b[1;1R"]` + "\n" + `[0.02,"i","from"]` + "\n" + `[0.03,"i","\r"]` + "\n"))
+	executed = nil
+	p = New(executor, func(_ Document, results chan []Suggest) { results <- nil },
+		OptionParser(replay),
+		OptionWriter(NewStreamWriter(ioutil.Discard)),
+		OptionSignals(),
+	)
+	p.Run()
+	if !reflect.DeepEqual(executed, []string{"from"}) {
+		t.Errorf("Should replay the input through the prompt, but got %#v", executed)
+	}
+}
+
+func TestNewReplayParserErrors(t *testing.T) {
+	scenarioTable := []struct {
+		in       string
+		expected string
+	}{
+		{in: "", expected: "replay: missing header"},
+		{in: `{"version": 1}`, expected: "replay: unsupported version 1"},
+		{in: `{"version": 2}` + "\n" + `[0.1, "i"]`, expected: "replay: line 2: expected [time, code, data]"},
+		{in: `{"version": 2}` + "\n" + `[0.1, "r", "wide"]`, expected: "replay: line 2: window size: expected integer"},
+	}
+	for _, s := range scenarioTable {
+		if _, err := NewReplayParser(strings.NewReader(s.in)); err == nil || err.Error() != s.expected {
+			t.Errorf("Should fail with %q, but got %v", s.expected, err)
+		}
+	}
+}
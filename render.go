@@ -4,108 +4,319 @@ import (
 	"math"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/aschey/go-prompt/internal/debug"
 	runewidth "github.com/mattn/go-runewidth"
 )
 
+const (
+	// monochromeSelectionMarker replaces the left padding of the selected suggestion in monochrome mode.
+	monochromeSelectionMarker = ">"
+	monochromeScrollbarThumb  = "|"
+
+	windowTooSmallMessage = "Your console window is too small..."
+)
+
 // Render to render prompt information from state of Buffer.
 type Render struct {
-	out                ConsoleWriter
-	prefix             string
-	livePrefixCallback func() (prefix string, useLivePrefix bool)
-	breakLineCallback  func(*Document)
-	title              string
-	row                uint16
-	col                uint16
-	statusBar          string
-
-	previousCursor int
-
-	// colors,
-	prefixTextColor              Color
-	prefixBGColor                Color
-	inputTextColor               Color
-	inputBGColor                 Color
-	previewSuggestionTextColor   Color
-	previewSuggestionBGColor     Color
-	suggestionTextColor          Color
-	suggestionBGColor            Color
-	selectedSuggestionTextColor  Color
-	selectedSuggestionBGColor    Color
-	descriptionTextColor         Color
-	descriptionBGColor           Color
-	selectedDescriptionTextColor Color
-	selectedDescriptionBGColor   Color
-	scrollbarThumbColor          Color
-	scrollbarBGColor             Color
+	out                        ConsoleWriter
+	prefix                     string
+	livePrefixCallback         func() (prefix string, useLivePrefix bool)
+	prefixSegments             []PrefixSegment
+	livePrefixSegmentsCallback func() (segments []PrefixSegment, useLivePrefix bool)
+	rprompt                    string
+	liveRPromptCallback        func() (rprompt string, useLiveRPrompt bool)
+	breakLineCallback          func(*Document)
+	// transient replaces the prefix of the accepted line with transientPrefix.
+	transient           bool
+	transientPrefix     string
+	transientPlainInput bool
+	title               string
+	// monochrome disables every color and display attribute.
+	monochrome bool
+	colorDepth ColorDepth
+	row        uint16
+	col        uint16
+	statusBar  string
+	// pendingKeys are the keys of a pending key sequence, shown after the status bar.
+	pendingKeys string
+	// statusShown tells whether a status bar is on the bottom row.
+	statusShown bool
+	theme       Theme
+	// status is the exit status of the last executed input.
+	status int
+	// recorder records what the writer flushes when it's set.
+	recorder *Recorder
+
+	// previous is the frame on the terminal, nil when it isn't known and the next frame is drawn from scratch.
+	previous *screen
+	// cursorX and cursorY are the position of the cursor on the terminal, relative to the first row of the prompt.
+	cursorX, cursorY int
+	// allocated is the number of rows below the first row of the prompt that it was drawn on.
+	allocated int
+	// pen is the style the terminal writes with, when penKnown.
+	pen          Style
+	penKnown     bool
+	cursorHidden bool
+	// previousScroll is the vertical scroll of the drop down in the previous frame.
+	previousScroll int
+
+	// fullScreen draws the prompt at the bottom of the alternate screen, below a region where output scrolls.
+	fullScreen bool
+	// outputRows is the height of the output region in full screen mode, 0 when the screen is to be set up.
+	outputRows int
+
+	// mouse enables the reporting of mouse events.
+	mouse bool
+	// originRow is the row of the screen the prompt starts on, when originKnown.
+	originRow   int
+	originKnown bool
+	// cprPending is set while waiting for the cursor position report asked for when the cursor was on row cprY.
+	cprPending bool
+	cprY       int
+	// reading tells whether the input is read in raw mode, so that the terminal can be asked where the cursor is.
+	reading bool
+	// inputX and inputY are where the input starts in the last frame, and menu where its drop down is.
+	inputX, inputY int
+	menu           menuArea
+}
+
+// menuArea is the part of the frame taken by the rows of the completion drop down.
+type menuArea struct {
+	x, y   int
+	width  int
+	rows   int
+	scroll int
+}
+
+// flush flushes the writer, recording what it writes when the session is recorded.
+func (r *Render) flush() error {
+	if w, ok := r.out.(bufferedWriter); ok && r.recorder != nil {
+		r.recorder.recordOutput(w.pending())
+	}
+	return r.out.Flush()
 }
 
 // Setup to initialize console output.
 func (r *Render) Setup() {
+	if w, ok := r.out.(interface{ SetColorDepth(ColorDepth) }); ok && r.colorDepth != ColorDepthAuto {
+		w.SetColorDepth(r.colorDepth)
+	}
 	if r.title != "" {
 		r.out.SetTitle(r.title)
-		debug.AssertNoError(r.out.Flush())
+		debug.AssertNoError(r.flush())
+	}
+	if _, ok := r.out.(FullScreenWriter); !ok {
+		r.fullScreen = false
+	}
+	if _, ok := r.out.(MouseWriter); !ok {
+		r.mouse = false
+	}
+	if r.fullScreen {
+		r.out.(FullScreenWriter).EnterAlternateScreen()
+		r.outputRows = 0
+		debug.AssertNoError(r.flush())
+	}
+	r.reportMouse(true)
+}
+
+// reportMouse turns the reporting of mouse events on or off, when the mouse is enabled.
+func (r *Render) reportMouse(enabled bool) {
+	if !r.mouse {
+		return
+	}
+	if enabled {
+		r.out.(MouseWriter).EnableMouse()
+	} else {
+		r.out.(MouseWriter).DisableMouse()
+	}
+	debug.AssertNoError(r.flush())
+}
+
+// framePosition returns the position in the frame of the column x and the row y of the screen.
+// It returns false until the row the prompt starts on is known.
+func (r *Render) framePosition(x, y int) (int, int, bool) {
+	if !r.originKnown || r.previous == nil {
+		return 0, 0, false
+	}
+	return x, y - r.originRow, true
+}
+
+// suggestionAt returns the index of the suggestion drawn at (x, y) in the frame.
+func (r *Render) suggestionAt(x, y int) (int, bool) {
+	m := r.menu
+	if y < m.y || y >= m.y+m.rows || x < m.x || x >= m.x+m.width {
+		return 0, false
+	}
+	return m.scroll + y - m.y, true
+}
+
+// inputIndexAt returns the index of the rune of text, the input, drawn at (x, y) in the frame.
+func (r *Render) inputIndexAt(x, y int, text string) (int, bool) {
+	if y == r.inputY && x < r.inputX {
+		return 0, true
 	}
+	return newScreen(r.width()).locate(r.inputX, r.inputY, text, x, y)
 }
 
-// getCurrentPrefix to get current prefix.
+// getCurrentPrefix to get the text of the current prefix on the input line.
 // If live-prefix is enabled, return live-prefix.
 func (r *Render) getCurrentPrefix() string {
-	if prefix, ok := r.livePrefixCallback(); ok {
-		return prefix
+	lines := splitPrefixLines(r.getCurrentPrefixSegments())
+	return prefixText(lines[len(lines)-1])
+}
+
+// getCurrentRPrompt to get current right prompt.
+// If live right prompt is enabled, return it.
+func (r *Render) getCurrentRPrompt() string {
+	if r.liveRPromptCallback != nil {
+		if rprompt, ok := r.liveRPromptCallback(); ok {
+			return rprompt
+		}
+	}
+	return r.rprompt
+}
+
+// drawRPrompt draws the right prompt at the right edge of row y, lineEnd being where the input ends on it.
+// It's skipped when the input reaches into it, like zsh's RPROMPT.
+func (r *Render) drawRPrompt(s *screen, lineEnd, y int) {
+	rprompt := strings.Replace(r.getCurrentRPrompt(), "\x1b", "?", -1)
+	if rprompt == "" {
+		return
+	}
+	w := runewidth.StringWidth(rprompt)
+	// Keep a space between the input and the right prompt, and leave the last column empty
+	// so the terminal doesn't wrap.
+	if lineEnd+1+w+1 > s.width {
+		return
 	}
-	return r.prefix
+	s.write(s.width-1-w, y, rprompt, r.theme.RPrompt)
 }
 
-func (r *Render) renderPrefix() {
-	r.out.SetColor(r.prefixTextColor, r.prefixBGColor, false)
-	r.out.WriteStr(r.getCurrentPrefix())
-	r.out.SetColor(DefaultColor, DefaultColor, false)
+// drawPrefix draws the prefix, starting with the lines above the input,
+// and returns the position the input starts at.
+func (r *Render) drawPrefix(s *screen) (x, y int) {
+	return drawPrefixLines(s, r.getCurrentPrefixSegments())
+}
+
+func drawPrefixLines(s *screen, segments []PrefixSegment) (x, y int) {
+	lines := splitPrefixLines(segments)
+	for _, line := range lines[:len(lines)-1] {
+		_, y = drawPrefixSegments(s, 0, y, line)
+		y++
+	}
+	return drawPrefixSegments(s, 0, y, lines[len(lines)-1])
 }
 
 // TearDown to clear title and erasing.
 func (r *Render) TearDown() {
 	r.out.ClearTitle()
-	r.out.EraseDown()
-	debug.AssertNoError(r.out.Flush())
-}
-
-func (r *Render) prepareArea(lines int) {
-	for i := 0; i < lines; i++ {
-		r.out.ScrollDown()
+	if r.mouse {
+		r.out.(MouseWriter).DisableMouse()
 	}
-	for i := 0; i < lines; i++ {
-		r.out.ScrollUp()
+	if w, ok := r.out.(FullScreenWriter); ok && r.fullScreen {
+		w.ResetScrollingRegion()
+		w.ExitAlternateScreen()
+	} else {
+		r.out.EraseDown()
 	}
+	debug.AssertNoError(r.flush())
 }
 
 // UpdateWinSize called when window size is changed.
 func (r *Render) UpdateWinSize(ws *WinSize) {
+	if ws.Col != r.col {
+		// Rows wrap at another column now, so the frame on the terminal no longer matches the previous one.
+		r.previous = nil
+	}
+	if r.fullScreen && (ws.Col != r.col || ws.Row != r.row) {
+		r.outputRows = 0
+	}
 	r.row = ws.Row
 	r.col = ws.Col
+	if w := r.width(); r.cursorX >= w && w > 0 {
+		r.cursorX = w - 1
+	}
 }
 
 func (r *Render) renderWindowTooSmall() {
 	r.out.CursorGoTo(0, 0)
 	r.out.EraseScreen()
-	r.out.SetColor(DarkRed, White, false)
-	r.out.WriteStr("Your console window is too small...")
+	r.setStyle(r.theme.Warning)
+	r.out.WriteStr(windowTooSmallMessage)
+	r.resetStyle()
+	// The prompt is drawn again from the top of the screen once it fits.
+	r.outputRows = 0
+	r.forgetFrame()
+	r.advance(runewidth.StringWidth(windowTooSmallMessage))
+}
+
+// clearScreen clears the screen, similar to the clear command, and draws the prompt from its top from now on.
+func (r *Render) clearScreen() {
+	r.out.EraseScreen()
+	r.out.CursorGoTo(0, 0)
+	debug.AssertNoError(r.flush())
+	r.outputRows = 0
+	r.forgetFrame()
+}
+
+// renderError writes the error returned by the executor on its own line, where its output went.
+func (r *Render) renderError(err error) {
+	r.setStyle(r.theme.Error)
+	r.out.WriteStr(err.Error())
+	r.resetStyle()
+	r.out.WriteRawStr("\n")
+	r.penKnown = false
+	debug.AssertNoError(r.flush())
+}
+
+// invalidate has the next frame drawn from scratch, over the previous one.
+func (r *Render) invalidate() {
+	r.previous = nil
+	r.penKnown = false
+}
+
+// forgetFrame forgets the frame on the terminal after something else was written to it,
+// leaving the cursor at the start of a row where the prompt is drawn from now on.
+func (r *Render) forgetFrame() {
+	r.previous = nil
+	r.cursorX, r.cursorY = 0, 0
+	r.allocated = 1
+	r.penKnown = false
+	r.originKnown = false
+	r.cprPending = false
+	r.menu = menuArea{}
 }
 
-var prevVerticalScroll = 0
+// width returns the number of columns the prompt is drawn on.
+// The legacy Windows console wraps as soon as the last column is written, so it's left empty there.
+func (r *Render) width() int {
+	col := int(r.col)
+	if runtime.GOOS == "windows" && col > 1 {
+		// WT_SESSION indicates Windows Terminal, which is more rational than the older cmd or ps terminals
+		if _, ok := os.LookupEnv("WT_SESSION"); !ok {
+			return col - 1
+		}
+	}
+	return col
+}
 
-func (r *Render) renderCompletion(buf *Buffer, completions *CompletionManager) {
-	suggestions := completions.GetSuggestions()
-	if len(completions.GetSuggestions()) == 0 {
+// drawCompletion draws the drop down below the cursor, along with the documentation of the selected suggestion.
+func (r *Render) drawCompletion(s *screen, completions *CompletionManager) {
+	r.menu = menuArea{}
+	// The headers are drawn along with the suggestions.
+	suggestions := completions.tmp
+	if len(suggestions) == 0 {
 		return
 	}
 	prefix := r.getCurrentPrefix()
 
-	maxWidth := int(r.col) - runewidth.StringWidth(prefix) - 1 // -1 means a width of scrollbar
+	maxWidth := s.width - runewidth.StringWidth(prefix) - 1 // -1 means a width of scrollbar
 
 	formatted, width := formatSuggestions(
 		suggestions,
+		completions.headers,
 		maxWidth,
 		int(completions.maxTextWidth),
 		int(completions.maxDescriptionWidth),
@@ -119,17 +330,30 @@ func (r *Render) renderCompletion(buf *Buffer, completions *CompletionManager) {
 	}
 
 	formatted = formatted[completions.verticalScroll : completions.verticalScroll+windowHeight]
-	if r.statusBar == "" {
-		r.prepareArea(windowHeight)
-	} else {
-		// reserve extra line for status bar and another to have separation
-		r.prepareArea(windowHeight + 2)
+
+	// The documentation goes to the right of the drop down when there is room for it, below it otherwise.
+	var docLines []string
+	docWidth, docHeight := 0, 0
+	if doc, ok := completions.Documentation(); ok {
+		if w := s.width - width; w >= documentationMinWidth {
+			docWidth = w
+			if docWidth > documentationMaxWidth {
+				docWidth = documentationMaxWidth
+			}
+			docLines = completions.documentationLines(doc, docWidth-leftMargin, windowHeight)
+		} else {
+			docLines = completions.documentationLines(doc, width-leftMargin, int(completions.max))
+			docHeight = len(docLines)
+		}
 	}
+	rowWidth := width + docWidth
 
-	cursor := runewidth.StringWidth(prefix) + runewidth.StringWidth(buf.Document().TextBeforeCursor())
-	x, _ := r.toPos(cursor)
-	if x+width >= int(r.col) {
-		cursor = r.backward(cursor, x+width-int(r.col))
+	x := s.cursorX
+	if x+rowWidth > s.width {
+		x = s.width - rowWidth
+	}
+	if x < 0 {
+		x = 0
 	}
 
 	contentHeight := len(completions.tmp)
@@ -143,52 +367,108 @@ func (r *Render) renderCompletion(buf *Buffer, completions *CompletionManager) {
 	// If scrolling up, use ceiling operation to ensure the scrollbar is only at the top when the first row is shown
 	// otherwise use floor operation
 	var scrollbarTop int
-	if prevVerticalScroll > completions.verticalScroll {
+	if r.previousScroll > completions.verticalScroll {
 		scrollbarTop = int(math.Ceil(scrollbarPos))
 	} else {
 		scrollbarTop = int(math.Floor(scrollbarPos))
 	}
 
+	r.menu = menuArea{x: x, y: s.cursorY + 1, width: width, rows: windowHeight, scroll: completions.verticalScroll}
+
 	isScrollThumb := func(row int) bool {
 		return scrollbarTop <= row && row < scrollbarTop+scrollbarHeight
 	}
 	selected := completions.selected - completions.verticalScroll
-	r.out.SetColor(White, Cyan, false)
 	for i := 0; i < windowHeight; i++ {
-		r.out.CursorDown(1)
-		if i == selected {
-			r.out.SetColor(r.selectedSuggestionTextColor, r.selectedSuggestionBGColor, true)
+		y := s.cursorY + 1 + i
+		var end int
+		if completions.isHeader(completions.verticalScroll + i) {
+			end, _ = s.write(x, y, formatted[i].Text+formatted[i].Description, r.theme.GroupHeader)
 		} else {
-			r.out.SetColor(r.suggestionTextColor, r.suggestionBGColor, false)
+			text := formatted[i].Text
+			if r.monochrome && i == selected {
+				text = monochromeSelectionMarker + text[len(leftPrefix):]
+			}
+			end, _ = s.write(x, y, text, r.suggestionStyle(formatted[i].Style, i == selected))
+
+			description := r.theme.Description
+			if i == selected {
+				description = r.theme.SelectedDescription
+			}
+			end, _ = s.write(end, y, formatted[i].Description, description)
 		}
-		r.out.WriteStr(formatted[i].Text)
 
-		if i == selected {
-			r.out.SetColor(r.selectedDescriptionTextColor, r.selectedDescriptionBGColor, false)
-		} else {
-			r.out.SetColor(r.descriptionTextColor, r.descriptionBGColor, false)
+		switch {
+		case r.monochrome && isScrollThumb(i):
+			end, _ = s.write(end, y, monochromeScrollbarThumb, Style{})
+		case r.monochrome:
+			end, _ = s.write(end, y, " ", Style{})
+		case isScrollThumb(i):
+			end, _ = s.write(end, y, " ", Style{BGColor: r.theme.ScrollbarThumb.BGColor})
+		default:
+			end, _ = s.write(end, y, " ", Style{BGColor: r.theme.ScrollbarBG.BGColor})
 		}
-		r.out.WriteStr(formatted[i].Description)
 
-		if isScrollThumb(i) {
-			r.out.SetColor(DefaultColor, r.scrollbarThumbColor, false)
-		} else {
-			r.out.SetColor(DefaultColor, r.scrollbarBGColor, false)
+		if docWidth > 0 {
+			line := ""
+			if i < len(docLines) {
+				line = docLines[i]
+			}
+			r.drawDocumentationLine(s, end, y, line, docWidth)
 		}
-		r.out.WriteStr(" ")
-		r.out.SetColor(DefaultColor, DefaultColor, false)
+	}
 
-		r.lineWrap(cursor + width)
-		r.backward(cursor+width, width)
+	for i := 0; i < docHeight; i++ {
+		r.drawDocumentationLine(s, x, s.cursorY+1+windowHeight+i, docLines[i], width)
+	}
+	r.previousScroll = completions.verticalScroll
+}
+
+// drawDocumentationLine draws a line of the documentation pane, padded to width.
+func (r *Render) drawDocumentationLine(s *screen, x, y int, line string, width int) {
+	s.write(x, y, leftPrefix+runewidth.FillRight(line, width-leftMargin)+leftSuffix, r.theme.Documentation)
+}
+
+// suggestionStyle returns the style of the text column of a suggestion,
+// applying the suggestion's own style on top of the drop down colors.
+func (r *Render) suggestionStyle(s *Style, selected bool) Style {
+	base := r.theme.Suggestion
+	if selected {
+		base = r.theme.SelectedSuggestion
+	}
+	if s == nil {
+		return base
+	}
+	own := *s
+	if selected {
+		own.TextColor, own.BGColor = DefaultColor, DefaultColor
 	}
+	return own.merge(base)
+}
 
-	if x+width >= int(r.col) {
-		r.out.CursorForward(x + width - int(r.col))
+// setStyle sets the style of the text written next. It writes nothing in monochrome mode.
+func (r *Render) setStyle(s Style) {
+	if r.monochrome {
+		return
+	}
+	if w, ok := r.out.(DisplayAttributesWriter); ok {
+		w.SetDisplayAttributes(s.TextColor, s.BGColor, s.attributes()...)
+	} else {
+		r.out.SetColor(s.TextColor, s.BGColor, s.Bold)
 	}
+}
 
-	r.out.CursorUp(windowHeight)
-	r.out.SetColor(DefaultColor, DefaultColor, false)
-	prevVerticalScroll = completions.verticalScroll
+func (r *Render) resetStyle() {
+	r.setStyle(Style{})
+}
+
+// setPen sets the style of the text written next, unless the terminal already writes with it.
+func (r *Render) setPen(s Style) {
+	if r.penKnown && r.pen == s {
+		return
+	}
+	r.setStyle(s)
+	r.pen, r.penKnown = s, true
 }
 
 // Render renders to the console.
@@ -198,9 +478,7 @@ func (r *Render) Render(buffer *Buffer, completion *CompletionManager) {
 	if r.col == 0 {
 		return
 	}
-	defer func() { debug.AssertNoError(r.out.Flush()) }()
-	r.prepareArea(2)
-	r.move(r.previousCursor, 0)
+	defer func() { debug.AssertNoError(r.flush()) }()
 
 	line := buffer.Text()
 	prefix := r.getCurrentPrefix()
@@ -215,103 +493,375 @@ func (r *Render) Render(buffer *Buffer, completion *CompletionManager) {
 		return
 	}
 
-	// Rendering
-	r.out.HideCursor()
-	defer r.out.ShowCursor()
+	s := r.drawInput(buffer, completion)
+	inputRows := len(s.rows)
+	r.drawCompletion(s, completion)
+	if r.fullScreen {
+		// The drop down always has room below the input, so that the input line doesn't move.
+		height := inputRows + int(completion.max)
+		if r.statusText() != "" {
+			height++
+		}
+		if height >= int(r.row) {
+			r.renderWindowTooSmall()
+			return
+		}
+		r.paintFullScreen(s, height)
+	} else {
+		if r.statusText() != "" {
+			// reserve extra line for status bar and another to have separation
+			s.grow(len(s.rows) + 2)
+			if len(s.rows) > r.allocated {
+				// The rows the prompt grows into may hold a copy of the status bar scrolled up
+				// from the bottom of the terminal, so everything is drawn again.
+				r.previous = nil
+			}
+		}
+		r.paint(s)
+		if r.reading && !r.originKnown && !r.cprPending {
+			r.askForCursorPosition()
+		}
+	}
+	r.renderStatusBar()
+}
 
-	r.renderPrefix()
-	r.out.SetColor(r.inputTextColor, r.inputBGColor, false)
-	r.out.WriteStr(line)
-	r.out.SetColor(DefaultColor, DefaultColor, false)
-	r.lineWrap(cursor)
+// paintFullScreen draws s on the last height rows of the screen, cutting what doesn't fit,
+// and limits scrolling to the rows above it where output goes.
+func (r *Render) paintFullScreen(s *screen, height int) {
+	if len(s.rows) > height {
+		s.rows = s.rows[:height]
+	}
+	s.grow(height)
+
+	outputRows := int(r.row) - height
+	if r.outputRows == 0 {
+		r.out.EraseScreen()
+		r.previous = nil
+	} else if outputRows < r.outputRows {
+		// Scroll the output up rather than covering its last lines.
+		r.out.(FullScreenWriter).SetScrollingRegion(1, r.outputRows)
+		r.out.CursorGoTo(r.outputRows, 1)
+		for i := outputRows; i < r.outputRows; i++ {
+			r.out.ScrollDown()
+		}
+	}
+	if outputRows != r.outputRows {
+		r.out.(FullScreenWriter).SetScrollingRegion(1, outputRows)
+		top := outputRows
+		if r.outputRows != 0 && r.outputRows < top {
+			top = r.outputRows
+		}
+		r.out.CursorGoTo(top+1, 1)
+		r.out.EraseDown()
+		r.previous = nil
+		r.outputRows = outputRows
+	}
 
-	r.out.EraseDown()
+	r.out.CursorGoTo(outputRows+1, 1)
+	r.cursorX, r.cursorY = 0, 0
+	r.allocated = height
+	r.originRow, r.originKnown = outputRows, true
+	r.paint(s)
+}
 
-	cursor = r.backward(cursor, runewidth.StringWidth(line)-buffer.DisplayCursorPosition())
+// writeOutput writes text at the bottom of the output region in full screen mode,
+// breaking the line after it so that the next output starts on a row of its own.
+func (r *Render) writeOutput(text []byte) {
+	r.out.CursorGoTo(r.outputRows, 1)
+	r.out.WriteRaw(text)
+	if len(text) > 0 && text[len(text)-1] != '\n' {
+		r.out.WriteRaw([]byte{'\r', '\n'})
+	}
+	r.penKnown = false
+}
 
-	r.renderCompletion(buffer, completion)
-	r.renderStatusBar()
-	if suggest, ok := completion.GetSelectedSuggestion(); ok {
-		cursor = r.backward(cursor, runewidth.StringWidth(buffer.Document().GetWordBeforeCursorUntilSeparator(completion.wordSeparator)))
+// writeRows writes the rows of s one after the other, breaking the line after each of them.
+func (r *Render) writeRows(s *screen) {
+	for _, row := range s.rows {
+		for _, c := range row[:rowLength(row)] {
+			if c.continuation {
+				continue
+			}
+			r.setPen(c.style)
+			if c.text == "" {
+				r.out.WriteStr(" ")
+			} else {
+				r.out.WriteStr(c.text)
+			}
+		}
+		r.setPen(Style{})
+		r.out.WriteRaw([]byte{'\r', '\n'})
+	}
+}
 
-		r.out.SetColor(r.previewSuggestionTextColor, r.previewSuggestionBGColor, false)
-		r.out.WriteStr(suggest.Text)
+// drawInput returns a frame showing the prefix and the input,
+// with a preview of the selected suggestion.
+func (r *Render) drawInput(buffer *Buffer, completion *CompletionManager) *screen {
+	s := newScreen(r.width())
+	x, y := r.drawPrefix(s)
+	lineY := y
+	r.inputX, r.inputY = x, y
 
-		rest := buffer.Document().TextAfterCursor()
+	doc := buffer.Document()
+	if suggest, ok := completion.GetSelectedSuggestion(); ok {
+		word := doc.GetWordBeforeCursorUntilSeparator(completion.wordSeparator)
+		x, y = s.write(x, y, strings.TrimSuffix(doc.TextBeforeCursor(), word), r.theme.Input)
+		x, y = s.write(x, y, suggest.Text, r.theme.PreviewSuggestion)
+		s.setCursor(x, y)
+		// Accepting the suggestion replaces the text after the cursor.
 		if suggest.Placeholder != "" {
-			r.out.SetColor(r.prefixTextColor, r.prefixBGColor, false)
-			r.out.WriteStr(" " + suggest.Placeholder)
-			r.out.EraseEndOfLine()
-			r.out.CursorBackward(runewidth.StringWidth(suggest.Placeholder) + 1)
-		} else {
+			x, y = s.write(x, y, " "+suggest.Placeholder, r.theme.Placeholder)
+		}
+	} else {
+		x, y = s.write(x, y, doc.TextBeforeCursor(), r.theme.Input)
+		s.setCursor(x, y)
+		x, y = s.write(x, y, doc.TextAfterCursor(), r.theme.Input)
+	}
+	if y == lineY {
+		r.drawRPrompt(s, x, y)
+	}
+	return s
+}
+
+// paint updates the terminal from the previous frame to s, writing only the cells that changed.
+func (r *Render) paint(s *screen) {
+	previous := r.previous
+	if previous == nil {
+		r.moveTo(0, 0)
+		r.setPen(Style{})
+		r.out.EraseDown()
+		previous = newScreen(s.width)
+	}
+
+	for y := range s.rows {
+		r.paintRow(y, s.rows[y], previous.row(y))
+	}
+	if len(previous.rows) > len(s.rows) {
+		r.hideCursor()
+		r.moveTo(0, len(s.rows))
+		r.setPen(Style{})
+		r.out.EraseDown()
+	}
+
+	r.setPen(Style{})
+	r.moveTo(s.cursorX, s.cursorY)
+	if r.cursorHidden {
+		r.out.ShowCursor()
+		r.cursorHidden = false
+	}
+	r.previous = s
+}
+
+// paintRow updates row y from the cells of the previous frame to next.
+func (r *Render) paintRow(y int, next, previous []cell) {
+	n, p := rowLength(next), rowLength(previous)
+	first, last := -1, -1
+	for x := 0; x < n || x < p; x++ {
+		if cellAt(next, x) != cellAt(previous, x) {
+			if first < 0 {
+				first = x
+			}
+			if x < n {
+				last = x
+			}
+		}
+	}
+	if first < 0 {
+		return
+	}
+	r.hideCursor()
+
+	if first < n {
+		// Wide characters are written whole, and so are the ones they overlapped.
+		for first > 0 && (cellAt(next, first).continuation || cellAt(previous, first).continuation) {
+			first--
+		}
+		for last+1 < n && (cellAt(next, last+1).continuation || cellAt(previous, last+1).continuation) {
+			last++
+		}
+		r.moveTo(first, y)
+		for x := first; x <= last; x++ {
+			c := next[x]
+			if c.continuation {
+				continue
+			}
+			r.setPen(c.style)
+			if c.text == "" {
+				r.out.WriteStr(" ")
+				r.advance(1)
+			} else {
+				r.out.WriteStr(c.text)
+				r.advance(c.width)
+			}
+		}
+	}
+	if p > n {
+		r.moveTo(n, y)
+		r.setPen(Style{})
+		r.out.EraseEndOfLine()
+	}
+}
+
+func (r *Render) hideCursor() {
+	if !r.cursorHidden {
+		r.out.HideCursor()
+		r.cursorHidden = true
+	}
+}
+
+// advance moves the known cursor position after n columns were written.
+// Writing the last column leaves the cursor on it until the next character is written.
+func (r *Render) advance(n int) {
+	r.cursorX += n
+	if w := r.width(); r.cursorX >= w {
+		r.cursorX = w - 1
+	}
+}
+
+// moveTo moves the cursor to (x, y) relative to the first row of the prompt,
+// breaking lines below the last row the prompt was drawn on when needed.
+func (r *Render) moveTo(x, y int) {
+	if r.allocated < 1 {
+		r.allocated = 1
+	}
+	if y >= r.allocated {
+		r.moveTo(r.cursorX, r.allocated-1)
+		r.setPen(Style{})
+		for r.allocated <= y {
+			r.lineFeed()
+			// The row may hold anything if the terminal didn't need to scroll.
 			r.out.EraseEndOfLine()
+			r.allocated++
+			if r.originKnown && r.originRow+r.allocated > int(r.row) {
+				// The terminal scrolled.
+				r.originRow = int(r.row) - r.allocated
+			}
 		}
+	}
 
-		r.out.SetColor(DefaultColor, DefaultColor, false)
-		cursor += runewidth.StringWidth(suggest.Text)
+	if dy := y - r.cursorY; dy > 0 {
+		r.out.CursorDown(dy)
+	} else if dy < 0 {
+		r.out.CursorUp(-dy)
+	}
+	if dx := x - r.cursorX; x == 0 && dx < 0 {
+		r.out.WriteRaw([]byte{'\r'})
+	} else if dx > 0 {
+		r.out.CursorForward(dx)
+	} else if dx < 0 {
+		r.out.CursorBackward(-dx)
+	}
+	r.cursorX, r.cursorY = x, y
+}
 
-		cursor += runewidth.StringWidth(rest)
-		r.lineWrap(cursor)
+// lineFeed moves the cursor to the start of the next row, scrolling the terminal at the bottom of the screen.
+func (r *Render) lineFeed() {
+	if r.cursorX > 0 {
+		r.out.WriteRaw([]byte{'\r'})
 	}
-	r.previousCursor = cursor
+	r.out.WriteRaw([]byte{'\n'})
+	r.cursorX = 0
+	r.cursorY++
 }
 
 func (r *Render) renderStatusBar() {
-	if r.statusBar == "" {
+	text := r.statusText()
+	if text == "" && !r.statusShown {
 		return
 	}
+	r.statusShown = text != ""
 	r.out.SaveCursor()
 	defer func() {
 		r.out.UnSaveCursor()
 		r.out.CursorUp(0)
 	}()
 
-	if r.statusBar != "" {
-		r.out.CursorDown(int(r.row))
-		r.out.CursorBackward(int(r.col))
-		r.out.WriteRawStr(r.statusBar)
+	r.out.CursorDown(int(r.row))
+	r.out.CursorBackward(int(r.col))
+	// The status bar may be shorter than the one it replaces.
+	r.out.EraseLine()
+	r.out.WriteRawStr(text)
+}
+
+// statusText returns the status bar, followed by the keys of a pending key sequence.
+func (r *Render) statusText() string {
+	switch {
+	case r.pendingKeys == "":
+		return r.statusBar
+	case r.statusBar == "":
+		return r.pendingKeys
 	}
+	return r.statusBar + " " + r.pendingKeys
 }
 
 // BreakLine to break line.
 func (r *Render) BreakLine(buffer *Buffer) {
-	// Erasing and Render
-	cursor := runewidth.StringWidth(buffer.Document().TextBeforeCursor()) + runewidth.StringWidth(r.getCurrentPrefix())
-	r.clear(cursor)
-	r.renderPrefix()
-	r.out.SetColor(r.inputTextColor, r.inputBGColor, false)
-	r.out.WriteStr(buffer.Document().Text + "\n")
-	r.out.SetColor(DefaultColor, DefaultColor, false)
-	debug.AssertNoError(r.out.Flush())
+	switch {
+	case r.col == 0:
+	case r.fullScreen && r.outputRows != 0:
+		// The accepted line goes to the output, and the prompt is drawn again once the executor is done.
+		r.out.CursorGoTo(r.outputRows+1, 1)
+		r.out.EraseDown()
+		r.out.CursorGoTo(r.outputRows, 1)
+		r.writeRows(r.drawAccepted(buffer))
+		r.forgetFrame()
+	default:
+		r.paint(r.drawAccepted(buffer))
+		r.lineFeed()
+		r.forgetFrame()
+	}
+	debug.AssertNoError(r.flush())
 	if r.breakLineCallback != nil {
 		r.breakLineCallback(buffer.Document())
 	}
-
-	r.previousCursor = 0
-}
-
-// clear erases the screen from a beginning of input
-// even if there is line break which means input length exceeds a window's width.
-func (r *Render) clear(cursor int) {
-	r.move(cursor, 0)
-	r.out.EraseDown()
 }
 
-// backward moves cursor to backward from a current cursor position
-// regardless there is a line break.
-func (r *Render) backward(from, n int) int {
-	return r.move(from, from-n)
+// drawAccepted returns the frame left in the scrollback when the input is accepted.
+// The completion drop down and the right prompt are erased, leaving the prefix and the input,
+// and the cursor at the end of the input.
+func (r *Render) drawAccepted(buffer *Buffer) *screen {
+	s := newScreen(r.width())
+	var x, y int
+	if r.transient {
+		x, y = drawPrefixLines(s, r.plainPrefix(r.transientPrefix))
+	} else {
+		x, y = r.drawPrefix(s)
+	}
+	input := r.theme.Input
+	if r.transientPlainInput {
+		input = Style{}
+	}
+	x, y = s.write(x, y, buffer.Document().Text, input)
+	if x >= s.width {
+		x = s.width - 1
+	}
+	s.cursorX, s.cursorY = x, y
+	s.grow(y + 1)
+	return s
 }
 
-// move moves cursor to specified position from the beginning of input
-// even if there is a line break.
-func (r *Render) move(from, to int) int {
-	fromX, fromY := r.toPos(from)
-	toX, toY := r.toPos(to)
-
-	r.out.CursorUp(fromY - toY)
-	r.out.CursorBackward(fromX - toX)
-	return to
+// printAbove erases the prompt, prints text in its place and draws the prompt again below it.
+func (r *Render) printAbove(text []byte, buffer *Buffer, completion *CompletionManager) {
+	if len(text) == 0 {
+		return
+	}
+	if r.fullScreen && r.outputRows != 0 {
+		r.writeOutput(text)
+		r.Render(buffer, completion)
+		return
+	}
+	if r.col != 0 {
+		r.moveTo(0, 0)
+		r.setPen(Style{})
+		r.out.EraseDown()
+	}
+	r.out.WriteRaw(text)
+	if text[len(text)-1] != '\n' {
+		r.out.WriteRaw([]byte{'\n'})
+	}
+	debug.AssertNoError(r.flush())
+	r.forgetFrame()
+	r.Render(buffer, completion)
 }
 
 // toPos returns the relative position from the beginning of the string.
@@ -319,15 +869,3 @@ func (r *Render) toPos(cursor int) (x, y int) {
 	col := int(r.col)
 	return cursor % col, cursor / col
 }
-
-func (r *Render) lineWrap(cursor int) {
-	if runtime.GOOS == "windows" {
-		// WT_SESSION indicates Windows Terminal, which is more rational than the older cmd or ps terminals
-		if _, ok := os.LookupEnv("WT_SESSION"); !ok {
-			return
-		}
-	}
-	if cursor > 0 && cursor%int(r.col) == 0 {
-		r.out.WriteRaw([]byte{'\n'})
-	}
-}
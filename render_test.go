@@ -5,6 +5,8 @@ package prompt
 
 import (
 	"reflect"
+	"regexp"
+	"strings"
 	"syscall"
 	"testing"
 )
@@ -60,7 +62,7 @@ func TestFormatCompletion(t *testing.T) {
 	}
 
 	for _, s := range scenarioTable {
-		ac, width := formatSuggestions(s.completions, s.maxWidth, 0, 0)
+		ac, width := formatSuggestions(s.completions, nil, s.maxWidth, 0, 0)
 		if !reflect.DeepEqual(ac, s.expected) {
 			t.Errorf("Should be %#v, but got %#v", s.expected, ac)
 		}
@@ -77,24 +79,9 @@ func TestBreakLineCallback(t *testing.T) {
 		out: &PosixWriter{
 			fd: syscall.Stdin, // "write" to stdin just so we don't mess with the output of the tests
 		},
-		livePrefixCallback:           func() (string, bool) { return "", false },
-		prefixTextColor:              Blue,
-		prefixBGColor:                DefaultColor,
-		inputTextColor:               DefaultColor,
-		inputBGColor:                 DefaultColor,
-		previewSuggestionTextColor:   Green,
-		previewSuggestionBGColor:     DefaultColor,
-		suggestionTextColor:          White,
-		suggestionBGColor:            Cyan,
-		selectedSuggestionTextColor:  Black,
-		selectedSuggestionBGColor:    Turquoise,
-		descriptionTextColor:         Black,
-		descriptionBGColor:           Turquoise,
-		selectedDescriptionTextColor: White,
-		selectedDescriptionBGColor:   Cyan,
-		scrollbarThumbColor:          DarkGray,
-		scrollbarBGColor:             Cyan,
-		col:                          1,
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		col:                1,
 	}
 	b := NewBuffer()
 	r.BreakLine(b)
@@ -114,3 +101,287 @@ func TestBreakLineCallback(t *testing.T) {
 		t.Errorf("BreakLine callback not called, i should be 3")
 	}
 }
+
+type bufferWriter struct {
+	VT100Writer
+}
+
+func (w *bufferWriter) Flush() error {
+	return nil
+}
+
+func TestRenderRPrompt(t *testing.T) {
+	scenarioTable := []struct {
+		text     string
+		expected []string
+	}{
+		{
+			text:     "foo",
+			expected: []string{"> foo          main"},
+		},
+		{
+			text:     "select * fro",
+			expected: []string{"> select * fro main"},
+		},
+		{
+			text:     "select * from",
+			expected: []string{"> select * from"},
+		},
+		{
+			text:     "select * from users",
+			expected: []string{"> select * from user", "s"},
+		},
+	}
+
+	for _, s := range scenarioTable {
+		r := &Render{
+			prefix:             "> ",
+			livePrefixCallback: func() (string, bool) { return "", false },
+			rprompt:            "main",
+			theme:              DefaultTheme(),
+			col:                20,
+		}
+		b := NewBuffer()
+		b.InsertText(s.text, false, true)
+		sc := r.drawInput(b, NewCompletionManager(nil, 6))
+		if actual := screenLines(sc); !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("Should be %#v, but got %#v", s.expected, actual)
+		}
+	}
+}
+
+func TestDrawAcceptedTransient(t *testing.T) {
+	theme := DefaultTheme()
+	theme.Input = Style{TextColor: Yellow}
+	r := &Render{
+		livePrefixCallback: func() (string, bool) { return "", false },
+		prefixSegments: []PrefixSegment{
+			{Text: " ~/src/go-prompt \n", Style: Style{BGColor: Blue}},
+			{Text: "❯ "},
+		},
+		rprompt:             "main",
+		transient:           true,
+		transientPrefix:     "$ ",
+		transientPlainInput: true,
+		theme:               theme,
+		col:                 20,
+	}
+	b := NewBuffer()
+	b.InsertText("git status", false, true)
+	b.CursorLeft(3)
+
+	s := r.drawAccepted(b)
+	expected := []string{"$ git status"}
+	if actual := screenLines(s); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+	if s.cursorX != 12 || s.cursorY != 0 {
+		t.Errorf("Should leave the cursor at the end of the input, but got (%d, %d)", s.cursorX, s.cursorY)
+	}
+	if style := s.rows[0][2].style; style != (Style{}) {
+		t.Errorf("Should draw the input without its style, but got %#v", style)
+	}
+}
+
+func TestDrawInputPreview(t *testing.T) {
+	r := &Render{
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		col:                20,
+	}
+	b := NewBuffer()
+	b.InsertText("sel users", false, true)
+	b.CursorLeft(6)
+	c := NewCompletionManager(nil, 6)
+	c.SetResults([]Suggest{{Text: "select"}})
+	c.Next()
+
+	// Accepting the suggestion replaces the text after the cursor, so it isn't previewed.
+	s := r.drawInput(b, c)
+	expected := []string{"> select"}
+	if actual := screenLines(s); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+	if s.cursorX != 8 {
+		t.Errorf("Should leave the cursor after the suggestion, but got %d", s.cursorX)
+	}
+}
+
+// basicWriter only has the methods of ConsoleWriter.
+type basicWriter struct {
+	ConsoleWriter
+}
+
+func TestRenderBasicWriter(t *testing.T) {
+	w := &bufferWriter{}
+	r := &Render{
+		out:                basicWriter{w},
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		fullScreen:         true,
+		mouse:              true,
+		row:                12,
+		col:                20,
+	}
+	r.theme.Input = Style{TextColor: Red, Italic: true}
+	r.Setup()
+	if r.fullScreen || r.mouse {
+		t.Errorf("Should turn off what the writer can't do, but got full screen %t and mouse %t", r.fullScreen, r.mouse)
+	}
+
+	b := NewBuffer()
+	b.InsertText("ls", false, true)
+	r.Render(b, NewCompletionManager(nil, 3))
+	r.TearDown()
+	if out := string(w.buffer); strings.Contains(out, "\x1b[0;3;") || !strings.Contains(out, "\x1b[0;91;49mls") {
+		t.Errorf("Should draw the input with SetColor, but got %q", out)
+	}
+}
+
+func TestRenderFullScreen(t *testing.T) {
+	w := &bufferWriter{}
+	r := &Render{
+		out:                w,
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		fullScreen:         true,
+		row:                12,
+		col:                20,
+	}
+	b := NewBuffer()
+	c := NewCompletionManager(nil, 3)
+
+	r.Render(b, c)
+	if out := string(w.buffer); !strings.Contains(out, "\x1b[1;8r\x1b[9;1H\x1b[J\x1b[9;1H") {
+		t.Errorf("Should put the prompt below 8 rows of output, but got %q", out)
+	}
+
+	w.buffer = nil
+	b.InsertText("select * from users", false, true)
+	r.Render(b, c)
+	if out := string(w.buffer); !strings.Contains(out, "\x1b[1;8r\x1b[8;1H\x1bD\x1b[1;7r") {
+		t.Errorf("Should scroll the output up when the input wraps, but got %q", out)
+	}
+
+	w.buffer = nil
+	r.BreakLine(b)
+	if out := string(w.buffer); !strings.Contains(out, "\x1b[7;1H\x1b[0;94;49m> \x1b[0;39;49mselect * from user\r\ns\r\n") {
+		t.Errorf("Should write the accepted line to the output, but got %q", out)
+	}
+
+	w.buffer = nil
+	r.TearDown()
+	if out := string(w.buffer); !strings.HasSuffix(out, "\x1b[r\x1b[?1049l") {
+		t.Errorf("Should restore the original screen, but got %q", out)
+	}
+}
+
+func TestRenderOnlyWritesChanges(t *testing.T) {
+	w := &bufferWriter{}
+	r := &Render{
+		out:                w,
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		row:                20,
+		col:                40,
+	}
+	b := NewBuffer()
+	c := NewCompletionManager(nil, 6)
+	b.InsertText("select", false, true)
+	r.Render(b, c)
+
+	w.buffer = nil
+	r.Render(b, c)
+	if len(w.buffer) != 0 {
+		t.Errorf("Should not write anything when nothing changed, but got %q", w.buffer)
+	}
+
+	b.InsertText(" *", false, true)
+	r.Render(b, c)
+	if out := string(w.buffer); !strings.Contains(out, " *") || strings.Contains(out, "select") {
+		t.Errorf("Should only write the inserted text, but got %q", out)
+	}
+
+	w.buffer = nil
+	b.DeleteBeforeCursor(2)
+	r.Render(b, c)
+	if out := string(w.buffer); !strings.Contains(out, "\x1b[K") || strings.Contains(out, "select") {
+		t.Errorf("Should only erase the deleted text, but got %q", out)
+	}
+}
+
+func TestRenderMonochrome(t *testing.T) {
+	w := &bufferWriter{}
+	r := &Render{
+		out:                w,
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		monochrome:         true,
+		row:                20,
+		col:                40,
+	}
+	c := NewCompletionManager(nil, 6)
+	c.SetResults([]Suggest{{Text: "select"}, {Text: "from"}})
+	c.Next()
+
+	r.Render(NewBuffer(), c)
+	out := string(w.buffer)
+	if sgr := regexp.MustCompile(`\x1b\[[0-9;]*m`).FindString(out); sgr != "" {
+		t.Errorf("Should not write any SGR sequence, but got %q", sgr)
+	}
+	if !strings.Contains(out, ">select ") || !strings.Contains(out, " from ") {
+		t.Errorf("Should mark the selected suggestion with >, but got %q", out)
+	}
+}
+
+type countingWriter struct {
+	VT100Writer
+	n int
+}
+
+func (w *countingWriter) Flush() error {
+	w.n += len(w.buffer)
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+func BenchmarkRenderKeystroke(b *testing.B) {
+	w := &countingWriter{}
+	r := &Render{
+		out:                w,
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		row:                40,
+		col:                80,
+	}
+	suggestions := []Suggest{
+		{Text: "select", Description: "Query rows"},
+		{Text: "set", Description: "Change a setting"},
+		{Text: "show", Description: "Show server information"},
+		{Text: "from", Description: "Pick a table"},
+		{Text: "users", Description: "The users table"},
+		{Text: "where", Description: "Filter rows"},
+	}
+	input := "select name from users where id = 1"
+	c := NewCompletionManager(nil, 6)
+	buf := NewBuffer()
+	r.Render(buf, c)
+	w.n = 0
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(input) == 0 {
+			buf = NewBuffer()
+		}
+		buf.InsertText(input[i%len(input):i%len(input)+1], false, true)
+		c.SetResults(FilterHasPrefix(suggestions, buf.Document().GetWordBeforeCursor(), true))
+		r.Render(buf, c)
+	}
+	b.ReportMetric(float64(w.n)/float64(b.N), "bytes/keystroke")
+}
@@ -0,0 +1,138 @@
+package prompt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// replayEvent is an input or a window size change of a recording.
+type replayEvent struct {
+	at      time.Duration
+	input   []byte
+	winSize *WinSize
+}
+
+// ReplayParser is a ConsoleParser feeding the input recorded by a Recorder back to a prompt,
+// at the time it was read, with the window size changes in between.
+// Use OptionSignals() with it, so that the window size of the terminal doesn't get in the way.
+type ReplayParser struct {
+	mu      sync.Mutex
+	events  []replayEvent
+	start   time.Time
+	started bool
+	winSize WinSize
+	resized chan struct{}
+	now     func() time.Time
+}
+
+// NewReplayParser reads the input recorded by a Recorder from r.
+func NewReplayParser(r io.Reader) (*ReplayParser, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("replay: missing header")
+	}
+	var h castHeader
+	if err := json.Unmarshal(s.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("replay: header: %v", err)
+	}
+	if h.Version != 2 {
+		return nil, fmt.Errorf("replay: unsupported version %d", h.Version)
+	}
+
+	p := &ReplayParser{
+		winSize: WinSize{Col: uint16(h.Width), Row: uint16(h.Height)},
+		resized: make(chan struct{}, 1),
+		now:     time.Now,
+	}
+	for n := 2; s.Scan(); n++ {
+		var e []interface{}
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("replay: line %d: %v", n, err)
+		}
+		if len(e) != 3 {
+			return nil, fmt.Errorf("replay: line %d: expected [time, code, data]", n)
+		}
+		t, ok1 := e[0].(float64)
+		code, ok2 := e[1].(string)
+		data, ok3 := e[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("replay: line %d: expected [time, code, data]", n)
+		}
+		at := time.Duration(t * float64(time.Second))
+		switch code {
+		case "i":
+			p.events = append(p.events, replayEvent{at: at, input: []byte(data)})
+		case "r":
+			var ws WinSize
+			if _, err := fmt.Sscanf(data, "%dx%d", &ws.Col, &ws.Row); err != nil {
+				return nil, fmt.Errorf("replay: line %d: window size: %v", n, err)
+			}
+			p.events = append(p.events, replayEvent{at: at, winSize: &ws})
+		}
+	}
+	return p, s.Err()
+}
+
+// Setup should be called before starting input
+func (p *ReplayParser) Setup() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		p.started = true
+		p.start = p.now()
+	}
+	return nil
+}
+
+// TearDown should be called after stopping input
+func (p *ReplayParser) TearDown() error {
+	return nil
+}
+
+// Read returns byte array.
+// The input following a window size change is held until the prompt took in the change, so that they're handled
+// in the order they were recorded.
+func (p *ReplayParser) Read() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.resized) > 0 {
+		return nil, errNoInput
+	}
+	if len(p.events) == 0 {
+		return nil, io.EOF
+	}
+	e := p.events[0]
+	if !p.started || p.now().Sub(p.start) < e.at {
+		return nil, errNoInput
+	}
+	p.events = p.events[1:]
+	if e.winSize == nil {
+		return e.input, nil
+	}
+	p.winSize = *e.winSize
+	p.resized <- struct{}{}
+	return nil, errNoInput
+}
+
+// GetWinSize returns WinSize object to represent width and height of terminal.
+func (p *ReplayParser) GetWinSize() *WinSize {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ws := p.winSize
+	return &ws
+}
+
+// Resized is signaled when the window size changes.
+func (p *ReplayParser) Resized() <-chan struct{} {
+	return p.resized
+}
+
+var _ ConsoleParser = &ReplayParser{}
@@ -0,0 +1,164 @@
+package prompt
+
+import (
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// cell is a character drawn on the terminal, with its style.
+// The zero value is an empty cell.
+type cell struct {
+	text  string
+	width int
+	style Style
+	// continuation marks the right half of a wide character.
+	continuation bool
+}
+
+// screen is an in-memory model of the rows the prompt is drawn on.
+// Row 0 is the first row of the prefix, and positions are relative to its first column.
+type screen struct {
+	width   int
+	rows    [][]cell
+	cursorX int
+	cursorY int
+}
+
+func newScreen(width int) *screen {
+	return &screen{width: width, rows: [][]cell{nil}}
+}
+
+// row returns the cells of row y. Cells past the end of a row are empty.
+func (s *screen) row(y int) []cell {
+	if y < len(s.rows) {
+		return s.rows[y]
+	}
+	return nil
+}
+
+// grow makes sure the screen has at least n rows.
+func (s *screen) grow(n int) {
+	for len(s.rows) < n {
+		s.rows = append(s.rows, nil)
+	}
+}
+
+func (s *screen) set(x, y int, c cell) {
+	s.grow(y + 1)
+	row := s.rows[y]
+	for len(row) <= x {
+		row = append(row, cell{})
+	}
+	row[x] = c
+	s.rows[y] = row
+}
+
+// write draws text from (x, y), wrapping at the width of the screen the way the terminal does.
+// It returns the position following the text, which is past the last column when the text fills its row.
+func (s *screen) write(x, y int, text string, style Style) (int, int) {
+	return s.layout(x, y, text, func(_ int, r rune, x, y, w int) {
+		switch {
+		case w == 2:
+			s.set(x, y, cell{text: string(r), width: w, style: style})
+			s.set(x+1, y, cell{style: style, continuation: true})
+		case w == 1:
+			s.set(x, y, cell{text: string(r), width: w, style: style})
+		case r == '\n':
+			s.grow(y + 2)
+		case r >= ' ' && r != '\x7f':
+			// Combining characters join the character before them.
+			if i := s.previousCell(x, y); i >= 0 {
+				s.rows[y][i].text += string(r)
+			}
+		}
+	})
+}
+
+// layout calls f with the index, position and width of each rune of text drawn from (x, y),
+// and returns the position following the text.
+// Line breaks and other runes that take no room are reported where they are, with a width of 0.
+func (s *screen) layout(x, y int, text string, f func(i int, r rune, x, y, w int)) (int, int) {
+	for i, r := range []rune(text) {
+		w := 0
+		switch {
+		case r == '\x1b':
+			// The same replacement ConsoleWriter.WriteStr does.
+			r, w = '?', 1
+		case r >= ' ' && r != '\x7f':
+			w = runewidth.RuneWidth(r)
+		}
+		if w > 0 && x+w > s.width {
+			x, y = 0, y+1
+		}
+		f(i, r, x, y, w)
+		x += w
+		if r == '\n' {
+			x, y = 0, y+1
+		}
+	}
+	return x, y
+}
+
+// locate returns the index of the rune of text drawn from (x, y) that is at (tx, ty),
+// or the index following the last rune of row ty when it ends before tx.
+// It returns false when text isn't drawn on row ty.
+func (s *screen) locate(x, y int, text string, tx, ty int) (int, bool) {
+	if ty < y {
+		return 0, false
+	}
+	index := -1
+	n := 0
+	_, endY := s.layout(x, y, text, func(i int, r rune, x, y, w int) {
+		n = i + 1
+		if index < 0 && (y > ty || y == ty && (x+w > tx || r == '\n')) {
+			index = i
+		}
+	})
+	if ty > endY {
+		return 0, false
+	}
+	if index < 0 {
+		index = n
+	}
+	return index, true
+}
+
+// previousCell returns the index of the character before x on row y, or -1 if there is none.
+func (s *screen) previousCell(x, y int) int {
+	row := s.row(y)
+	if x > len(row) {
+		x = len(row)
+	}
+	for i := x - 1; i >= 0; i-- {
+		if !row[i].continuation {
+			return i
+		}
+	}
+	return -1
+}
+
+// setCursor sets where the cursor is left once the screen is drawn.
+// A position past the last column is the start of the next row.
+func (s *screen) setCursor(x, y int) {
+	if x >= s.width {
+		x, y = 0, y+1
+	}
+	s.cursorX, s.cursorY = x, y
+	s.grow(y + 1)
+}
+
+// cellAt returns the cell at x of row, empty past its end.
+func cellAt(row []cell, x int) cell {
+	if x < len(row) {
+		return row[x]
+	}
+	return cell{}
+}
+
+// rowLength returns the number of cells of row up to the last one that's not empty.
+func rowLength(row []cell) int {
+	n := len(row)
+	for n > 0 && row[n-1] == (cell{}) {
+		n--
+	}
+	return n
+}
@@ -0,0 +1,108 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// screenLines returns the text of the rows of s, empty cells being spaces.
+func screenLines(s *screen) []string {
+	lines := make([]string, len(s.rows))
+	for y, row := range s.rows {
+		var b strings.Builder
+		for _, c := range row[:rowLength(row)] {
+			switch {
+			case c.continuation:
+			case c.text == "":
+				b.WriteString(" ")
+			default:
+				b.WriteString(c.text)
+			}
+		}
+		lines[y] = b.String()
+	}
+	return lines
+}
+
+func TestScreenWrite(t *testing.T) {
+	scenarioTable := []struct {
+		text     string
+		x        int
+		expected []string
+		endX     int
+		endY     int
+	}{
+		{
+			text:     "hello",
+			x:        2,
+			expected: []string{"  hello"},
+			endX:     7,
+			endY:     0,
+		},
+		{
+			text:     "hello world",
+			x:        2,
+			expected: []string{"  hello wo", "rld"},
+			endX:     3,
+			endY:     1,
+		},
+		{
+			text:     "abcdefgh",
+			x:        2,
+			expected: []string{"  abcdefgh"},
+			endX:     10,
+			endY:     0,
+		},
+		{
+			text:     "あいうえお",
+			x:        1,
+			expected: []string{" あいうえ", "お"},
+			endX:     2,
+			endY:     1,
+		},
+		{
+			text:     "a\nb\x1b[0m",
+			x:        0,
+			expected: []string{"a", "b?[0m"},
+			endX:     5,
+			endY:     1,
+		},
+	}
+
+	for _, s := range scenarioTable {
+		sc := newScreen(10)
+		x, y := sc.write(s.x, 0, s.text, Style{})
+		if actual := screenLines(sc); !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("Should be %#v, but got %#v", s.expected, actual)
+		}
+		if x != s.endX || y != s.endY {
+			t.Errorf("Should end at (%d, %d), but got (%d, %d)", s.endX, s.endY, x, y)
+		}
+	}
+}
+
+func TestScreenLocate(t *testing.T) {
+	scenarioTable := []struct {
+		x, y     int
+		expected int
+		ok       bool
+	}{
+		{x: 2, y: 0, expected: 0, ok: true},
+		{x: 5, y: 0, expected: 3, ok: true},
+		{x: 9, y: 0, expected: 7, ok: true},
+		{x: 2, y: 1, expected: 10, ok: true},
+		{x: 8, y: 1, expected: 14, ok: true},
+		{x: 1, y: 2, expected: 16, ok: true},
+		{x: 0, y: 3},
+	}
+
+	// "> select *" / "from a" / "bc" once drawn 10 columns wide.
+	text := "select *from a\nbc"
+	for _, s := range scenarioTable {
+		actual, ok := newScreen(10).locate(2, 0, text, s.x, s.y)
+		if actual != s.expected || ok != s.ok {
+			t.Errorf("(%d, %d): should be %d, %v, but got %d, %v", s.x, s.y, s.expected, s.ok, actual, ok)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package prompt
+
+import (
+	"os"
+	"syscall"
+)
+
+// InterruptAction is what the prompt does on an interrupt.
+type InterruptAction int
+
+const (
+	// InterruptCancelLine abandons the input and starts over on a new line.
+	InterruptCancelLine InterruptAction = iota
+	// InterruptExit exits Run.
+	InterruptExit
+	// InterruptIgnore does nothing.
+	InterruptIgnore
+	// InterruptCancelExecutor cancels the context of the running ExecutorContext.
+	// It cancels the line when nothing is running.
+	InterruptCancelExecutor
+)
+
+// Interrupt describes an interrupt: Ctrl+c typed while editing, or SIGINT.
+type Interrupt struct {
+	// Input is the text being edited, or the one being executed.
+	Input Document
+	// Signal is true for SIGINT, and false for Ctrl+c read from the input.
+	Signal bool
+	// Executing is true while an ExecutorContext runs. Ctrl+c is then always a SIGINT.
+	Executing bool
+}
+
+// InterruptHandler is called on an interrupt. It returns what the prompt does, and the code Run returns
+// with InterruptExit.
+type InterruptHandler func(Interrupt) (action InterruptAction, code int)
+
+// SignalHandler is called when the prompt receives a signal. It returns whether Run exits, and with which code.
+type SignalHandler func() (exit bool, code int)
+
+// DefaultInterruptHandler cancels the line on Ctrl+c, cancels the running ExecutorContext on SIGINT,
+// and otherwise exits with code 0 on SIGINT.
+func DefaultInterruptHandler(i Interrupt) (InterruptAction, int) {
+	if i.Executing {
+		return InterruptCancelExecutor, 0
+	}
+	if i.Signal {
+		return InterruptExit, 0
+	}
+	return InterruptCancelLine, 0
+}
+
+// signalSupported tells whether the prompt handles sig.
+func signalSupported(sig os.Signal) bool {
+	for _, s := range defaultSignals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// handleSignal handles a signal forwarded to the Run loop, and returns whether Run exits, and with which code.
+// Signals the prompt has no handler for are ignored.
+func (p *Prompt) handleSignal(sig os.Signal) (exit bool, code int) {
+	switch sig {
+	case syscall.SIGINT: // kill -SIGINT XXXX
+		return p.interrupt(true)
+	case syscall.SIGTERM: // kill -SIGTERM XXXX
+		if p.terminateHandler != nil {
+			return p.terminateHandler()
+		}
+		return true, 1
+	case syscall.SIGQUIT: // kill -SIGQUIT XXXX
+		if p.quitHandler != nil {
+			return p.quitHandler()
+		}
+		return true, 0
+	}
+	return false, 0
+}
+
+// interrupt handles Ctrl+c or SIGINT, and returns whether Run exits, and with which code.
+func (p *Prompt) interrupt(signal bool) (exit bool, code int) {
+	action, code := p.interruptAction(Interrupt{Input: *p.buf.Document(), Signal: signal})
+	switch action {
+	case InterruptCancelLine, InterruptCancelExecutor:
+		p.cancelLine()
+	case InterruptExit:
+		return true, code
+	}
+	return false, 0
+}
+
+// interruptAction asks the interrupt handler what to do.
+func (p *Prompt) interruptAction(i Interrupt) (InterruptAction, int) {
+	if p.interruptHandler != nil {
+		return p.interruptHandler(i)
+	}
+	return DefaultInterruptHandler(i)
+}
+
+// cancelLine abandons the input and starts over on a new line.
+func (p *Prompt) cancelLine() {
+	p.acceptCompletion()
+	p.renderer.BreakLine(p.buf)
+	p.buf = NewBuffer()
+	p.history.Clear()
+}
@@ -10,16 +10,33 @@ import (
 	"github.com/aschey/go-prompt/internal/debug"
 )
 
-func (p *Prompt) handleSignals(exitCh chan int, winSizeCh chan *WinSize, stop chan struct{}) {
+// jobControlSupported tells whether the process can be stopped and continued.
+const jobControlSupported = true
+
+// defaultSignals are the signals the prompt subscribes to unless told otherwise.
+var defaultSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGQUIT,
+	syscall.SIGWINCH,
+}
+
+// handleSignals forwards the signals the prompt subscribes to into the Run loop.
+func (p *Prompt) handleSignals(signalCh chan os.Signal, winSizeCh chan *WinSize, resumeCh chan struct{}, stop chan struct{}) {
 	in := p.in
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(
-		sigCh,
-		syscall.SIGINT,
-		syscall.SIGTERM,
-		syscall.SIGQUIT,
-		syscall.SIGWINCH,
-	)
+	signals := p.signals
+	if signals == nil {
+		signals = defaultSignals
+	}
+	if len(signals) > 0 {
+		// Notify relays every signal when given none.
+		signal.Notify(sigCh, signals...)
+	}
+	if p.jobControl {
+		signal.Notify(sigCh, syscall.SIGCONT)
+	}
+	defer signal.Stop(sigCh)
 
 	for {
 		select {
@@ -28,22 +45,23 @@ func (p *Prompt) handleSignals(exitCh chan int, winSizeCh chan *WinSize, stop ch
 			return
 		case s := <-sigCh:
 			switch s {
-			case syscall.SIGINT: // kill -SIGINT XXXX or Ctrl+c
-				debug.Log("Catch SIGINT")
-				exitCh <- 0
-
-			case syscall.SIGTERM: // kill -SIGTERM XXXX
-				debug.Log("Catch SIGTERM")
-				exitCh <- 1
-
-			case syscall.SIGQUIT: // kill -SIGQUIT XXXX
-				debug.Log("Catch SIGQUIT")
-				exitCh <- 0
-
 			case syscall.SIGWINCH:
 				debug.Log("Catch SIGWINCH")
 				winSizeCh <- in.GetWinSize()
+
+			case syscall.SIGCONT: // fg or kill -SIGCONT XXXX
+				debug.Log("Catch SIGCONT")
+				resumeCh <- struct{}{}
+
+			default:
+				debug.Log("Catch " + s.String())
+				signalCh <- s
 			}
 		}
 	}
 }
+
+// stopProcess stops the process group, like Ctrl+z does when the terminal isn't in raw mode.
+func stopProcess() {
+	debug.AssertNoError(syscall.Kill(0, syscall.SIGTSTP))
+}
@@ -0,0 +1,113 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestPromptHandleSignal(t *testing.T) {
+	newPrompt := func(opts ...Option) *Prompt {
+		p := &Prompt{
+			buf: NewBuffer(),
+			renderer: &Render{
+				out:                &bufferWriter{},
+				prefix:             "> ",
+				livePrefixCallback: func() (string, bool) { return "", false },
+				theme:              DefaultTheme(),
+				row:                20,
+				col:                40,
+			},
+			history:    NewHistory(),
+			completion: NewCompletionManager(nil, 6),
+		}
+		for _, opt := range opts {
+			opt(p)
+		}
+		p.buf.InsertText("select", false, true)
+		return p
+	}
+	cancel := func(Interrupt) (InterruptAction, int) { return InterruptCancelLine, 0 }
+	ignore := func() (bool, int) { return false, 0 }
+
+	scenarioTable := []struct {
+		name   string
+		opts   []Option
+		signal os.Signal
+		exit   bool
+		code   int
+		text   string
+	}{
+		{name: "default SIGINT", signal: syscall.SIGINT, exit: true, code: 0, text: "select"},
+		{name: "default SIGTERM", signal: syscall.SIGTERM, exit: true, code: 1, text: "select"},
+		{name: "default SIGQUIT", signal: syscall.SIGQUIT, exit: true, code: 0, text: "select"},
+		{name: "unhandled signal", signal: syscall.SIGUSR1, text: "select"},
+		{name: "SIGINT cancels the line", opts: []Option{OptionInterruptHandler(cancel)}, signal: syscall.SIGINT, text: ""},
+		{name: "SIGTERM ignored", opts: []Option{OptionTerminateHandler(ignore)}, signal: syscall.SIGTERM, text: "select"},
+		{
+			name:   "SIGQUIT exit code",
+			opts:   []Option{OptionQuitHandler(func() (bool, int) { return true, 3 })},
+			signal: syscall.SIGQUIT,
+			exit:   true,
+			code:   3,
+			text:   "select",
+		},
+	}
+
+	for _, s := range scenarioTable {
+		p := newPrompt(s.opts...)
+		exit, code := p.handleSignal(s.signal)
+		if exit != s.exit || code != s.code {
+			t.Errorf("[%s] Want exit %t with code %d, but got %t with %d", s.name, s.exit, s.code, exit, code)
+		}
+		if text := p.buf.Text(); text != s.text {
+			t.Errorf("[%s] Want input %q, but got %q", s.name, s.text, text)
+		}
+	}
+}
+
+func TestPromptControlC(t *testing.T) {
+	p := &Prompt{
+		buf: NewBuffer(),
+		renderer: &Render{
+			out:                &bufferWriter{},
+			prefix:             "> ",
+			livePrefixCallback: func() (string, bool) { return "", false },
+			theme:              DefaultTheme(),
+			row:                20,
+			col:                40,
+		},
+		history:    NewHistory(),
+		completion: NewCompletionManager(nil, 6),
+	}
+	p.buf.InsertText("select", false, true)
+	if exit, _ := p.feed([]byte{0x3}); exit || p.buf.Text() != "" {
+		t.Errorf("Should cancel the line by default, but got exit %t with input %q", exit, p.buf.Text())
+	}
+
+	var got Interrupt
+	OptionInterruptHandler(func(i Interrupt) (InterruptAction, int) {
+		got = i
+		return InterruptExit, 130
+	})(p)
+	p.buf.InsertText("from", false, true)
+	if exit, _ := p.feed([]byte{0x3}); !exit || p.exitCode != 130 {
+		t.Errorf("Should exit with code 130, but got exit %t with code %d", exit, p.exitCode)
+	}
+	if got.Signal || got.Input.Text != "from" {
+		t.Errorf("Should pass the input to the handler, but got %#v", got)
+	}
+}
+
+func TestOptionSignals(t *testing.T) {
+	p := &Prompt{}
+	if err := OptionSignals(syscall.SIGINT, syscall.SIGWINCH)(p); err != nil {
+		t.Errorf("Should accept the handled signals, but got %v", err)
+	}
+	if err := OptionSignals(syscall.SIGUSR1)(p); err == nil {
+		t.Error("Should reject a signal the prompt doesn't handle")
+	}
+}
@@ -10,14 +10,28 @@ import (
 	"github.com/aschey/go-prompt/internal/debug"
 )
 
-func (p *Prompt) handleSignals(exitCh chan int, winSizeCh chan *WinSize, stop chan struct{}) {
+// jobControlSupported tells whether the process can be stopped and continued.
+const jobControlSupported = false
+
+// defaultSignals are the signals the prompt subscribes to unless told otherwise.
+var defaultSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGQUIT,
+}
+
+// handleSignals forwards the signals the prompt subscribes to into the Run loop.
+func (p *Prompt) handleSignals(signalCh chan os.Signal, winSizeCh chan *WinSize, resumeCh chan struct{}, stop chan struct{}) {
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(
-		sigCh,
-		syscall.SIGINT,
-		syscall.SIGTERM,
-		syscall.SIGQUIT,
-	)
+	signals := p.signals
+	if signals == nil {
+		signals = defaultSignals
+	}
+	if len(signals) > 0 {
+		// Notify relays every signal when given none.
+		signal.Notify(sigCh, signals...)
+	}
+	defer signal.Stop(sigCh)
 
 	for {
 		select {
@@ -25,20 +39,11 @@ func (p *Prompt) handleSignals(exitCh chan int, winSizeCh chan *WinSize, stop ch
 			debug.Log("stop handleSignals")
 			return
 		case s := <-sigCh:
-			switch s {
-
-			case syscall.SIGINT: // kill -SIGINT XXXX or Ctrl+c
-				debug.Log("Catch SIGINT")
-				exitCh <- 0
-
-			case syscall.SIGTERM: // kill -SIGTERM XXXX
-				debug.Log("Catch SIGTERM")
-				exitCh <- 1
-
-			case syscall.SIGQUIT: // kill -SIGQUIT XXXX
-				debug.Log("Catch SIGQUIT")
-				exitCh <- 0
-			}
+			debug.Log("Catch " + s.String())
+			signalCh <- s
 		}
 	}
 }
+
+// stopProcess does nothing, as there's no job control on Windows.
+func stopProcess() {}
@@ -0,0 +1,127 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+// errNoInput is returned by StreamParser.Read when nothing was received since the last call.
+var errNoInput = errors.New("no input available")
+
+// StreamParser is a ConsoleParser reading from an io.Reader, such as a network connection.
+// It doesn't touch the terminal of the process: the window size is pushed with SetWinSize instead of
+// being read on SIGWINCH, so use OptionSignals() to keep the prompt away from the signals of the process.
+type StreamParser struct {
+	chunks chan []byte
+
+	mu      sync.Mutex
+	winSize WinSize
+	resized chan struct{}
+}
+
+// NewStreamParser returns a StreamParser reading from r until it fails, with a window of 80 columns and 25 rows.
+// Read returns io.EOF once r is exhausted, which ends Run.
+func NewStreamParser(r io.Reader) *StreamParser {
+	p := &StreamParser{
+		chunks:  make(chan []byte, 128),
+		winSize: WinSize{Row: 25, Col: 80},
+		resized: make(chan struct{}, 1),
+	}
+	go p.receive(r)
+	return p
+}
+
+// receive reads r in the background, as Read mustn't block.
+func (p *StreamParser) receive(r io.Reader) {
+	defer close(p.chunks)
+	buf := make([]byte, maxReadBytes)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			p.chunks <- append([]byte{}, buf[:n]...)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Setup should be called before starting input
+func (p *StreamParser) Setup() error {
+	return nil
+}
+
+// TearDown should be called after stopping input
+func (p *StreamParser) TearDown() error {
+	return nil
+}
+
+// Read returns byte array.
+func (p *StreamParser) Read() ([]byte, error) {
+	select {
+	case b, ok := <-p.chunks:
+		if !ok {
+			return nil, io.EOF
+		}
+		return b, nil
+	default:
+		return nil, errNoInput
+	}
+}
+
+// GetWinSize returns WinSize object to represent width and height of terminal.
+func (p *StreamParser) GetWinSize() *WinSize {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ws := p.winSize
+	return &ws
+}
+
+// SetWinSize sets the size of the remote terminal, and has the prompt drawn again to fit it.
+func (p *StreamParser) SetWinSize(ws WinSize) {
+	p.mu.Lock()
+	p.winSize = ws
+	p.mu.Unlock()
+	select {
+	case p.resized <- struct{}{}:
+	default:
+	}
+}
+
+// Resized is signaled when the window size changes.
+func (p *StreamParser) Resized() <-chan struct{} {
+	return p.resized
+}
+
+var _ ConsoleParser = &StreamParser{}
+
+// resizeNotifier is implemented by parsers that are told when the window size changes, rather than by SIGWINCH.
+type resizeNotifier interface {
+	Resized() <-chan struct{}
+}
+
+// StreamWriter is a ConsoleWriter writing VT100 escape sequences to an io.Writer, such as a network connection.
+// As there is no terminal driver in between, line feeds are written as CR LF.
+// The color depth is detected from the environment of the process, so set it with OptionColorDepth
+// to match the remote terminal.
+type StreamWriter struct {
+	VT100Writer
+	w io.Writer
+}
+
+// NewStreamWriter returns a StreamWriter writing to w.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// Flush to flush buffer
+func (w *StreamWriter) Flush() error {
+	b := bytes.Replace(w.buffer, []byte{'\n'}, []byte{'\r', '\n'}, -1)
+	w.buffer = []byte{}
+	_, err := w.w.Write(b)
+	return err
+}
+
+var _ ConsoleWriter = &StreamWriter{}
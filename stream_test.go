@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStreamParser(t *testing.T) {
+	r, w := io.Pipe()
+	p := NewStreamParser(r)
+	if _, err := p.Read(); err != errNoInput {
+		t.Errorf("Should not block without input, but got %v", err)
+	}
+
+	p.SetWinSize(WinSize{Row: 40, Col: 120})
+	select {
+	case <-p.Resized():
+	default:
+		t.Error("Should signal the new window size")
+	}
+	if ws := p.GetWinSize(); *ws != (WinSize{Row: 40, Col: 120}) {
+		t.Errorf("Want 120x40, but got %#v", ws)
+	}
+
+	w.Write([]byte("a"))
+	w.Close()
+	var got []byte
+	var err error
+	for err == nil || err == errNoInput {
+		var b []byte
+		b, err = p.Read()
+		got = append(got, b...)
+	}
+	if string(got) != "a" || err != io.EOF {
+		t.Errorf("Want \"a\" then io.EOF, but got %q and %v", got, err)
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewStreamWriter(&b)
+	w.WriteStr("a\nb")
+	w.Flush()
+	if b.String() != "a\r\nb" {
+		t.Errorf("Should write line feeds as CR LF, but got %q", b.String())
+	}
+}
+
+func TestRunOverStreams(t *testing.T) {
+	var wg sync.WaitGroup
+	inputs := [][]string{{"select 1;", "\r"}, {"select 2;", "\r", "select 3;", "\r"}}
+	executed := make([][]string, len(inputs))
+	outputs := make([]bytes.Buffer, len(inputs))
+	for i := range inputs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, w := io.Pipe()
+			go func() {
+				// Answer the request for the cursor position, so that Run doesn't wait for it.
+				w.Write([]byte("\x1b[1;1R"))
+				for _, key := range inputs[i] {
+					w.Write([]byte(key))
+				}
+				w.Close()
+			}()
+			p := New(func(in string, _ *Suggest, _ []Suggest) {
+				executed[i] = append(executed[i], in)
+			}, func(Document, chan []Suggest) {},
+				OptionParser(NewStreamParser(r)),
+				OptionWriter(NewStreamWriter(&outputs[i])),
+				OptionSignals(),
+			)
+			if code := p.Run(); code != 0 {
+				t.Errorf("[prompt %d] Should exit with code 0 at the end of the input, but got %d", i, code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	expected := [][]string{{"select 1;"}, {"select 2;", "select 3;"}}
+	if !reflect.DeepEqual(executed, expected) {
+		t.Errorf("Want %#v, but got %#v", expected, executed)
+	}
+	if out := outputs[1].String(); !strings.Contains(out, "select 3;") {
+		t.Errorf("Should draw the prompt on the stream, but got %q", out)
+	}
+}
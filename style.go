@@ -0,0 +1,56 @@
+package prompt
+
+// Style describes how a piece of text is drawn: its colors and display attributes.
+type Style struct {
+	TextColor  Color
+	BGColor    Color
+	Bold       bool
+	Italic     bool
+	Underline  bool
+	Reverse    bool
+	Dim        bool
+	CrossedOut bool
+}
+
+// attributes returns the display attributes enabled by s.
+// DisplayReset always comes first so that attributes of the previous cell don't leak.
+func (s Style) attributes() []DisplayAttribute {
+	attrs := []DisplayAttribute{DisplayReset}
+	if s.Bold {
+		attrs = append(attrs, DisplayBold)
+	}
+	if s.Dim {
+		attrs = append(attrs, DisplayLowIntensity)
+	}
+	if s.Italic {
+		attrs = append(attrs, DisplayItalic)
+	}
+	if s.Underline {
+		attrs = append(attrs, DisplayUnderline)
+	}
+	if s.Reverse {
+		attrs = append(attrs, DisplayReverse)
+	}
+	if s.CrossedOut {
+		attrs = append(attrs, DisplayCrossedOut)
+	}
+	return attrs
+}
+
+// merge returns base with the colors and attributes set in s applied on top of it.
+// DefaultColor in s keeps the color of base.
+func (s Style) merge(base Style) Style {
+	if s.TextColor != DefaultColor {
+		base.TextColor = s.TextColor
+	}
+	if s.BGColor != DefaultColor {
+		base.BGColor = s.BGColor
+	}
+	base.Bold = base.Bold || s.Bold
+	base.Italic = base.Italic || s.Italic
+	base.Underline = base.Underline || s.Underline
+	base.Reverse = base.Reverse || s.Reverse
+	base.Dim = base.Dim || s.Dim
+	base.CrossedOut = base.CrossedOut || s.CrossedOut
+	return base
+}
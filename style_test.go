@@ -0,0 +1,38 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStyleAttributes(t *testing.T) {
+	scenarioTable := []struct {
+		style    Style
+		expected []DisplayAttribute
+	}{
+		{
+			style:    Style{TextColor: Red},
+			expected: []DisplayAttribute{DisplayReset},
+		},
+		{
+			style:    Style{Bold: true, Underline: true, CrossedOut: true},
+			expected: []DisplayAttribute{DisplayReset, DisplayBold, DisplayUnderline, DisplayCrossedOut},
+		},
+	}
+
+	for _, s := range scenarioTable {
+		if actual := s.style.attributes(); !reflect.DeepEqual(actual, s.expected) {
+			t.Errorf("Should be %#v, but got %#v", s.expected, actual)
+		}
+	}
+}
+
+func TestStyleMerge(t *testing.T) {
+	base := Style{TextColor: White, BGColor: Cyan, Bold: true}
+
+	actual := Style{TextColor: Yellow, Italic: true}.merge(base)
+	expected := Style{TextColor: Yellow, BGColor: Cyan, Bold: true, Italic: true}
+	if actual != expected {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+}
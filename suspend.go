@@ -0,0 +1,53 @@
+package prompt
+
+import "github.com/aschey/go-prompt/internal/debug"
+
+// suspend restores the terminal and stops the process, like Ctrl+z in a shell.
+// The prompt is drawn again when the process is continued.
+func (p *Prompt) suspend() {
+	p.setShown(false, func() { p.renderer.suspend(p.buf) })
+	debug.AssertNoError(p.in.TearDown())
+	p.renderer.setReading(false)
+	p.suspended = true
+	stopProcess()
+}
+
+// resume sets the terminal up again once the process is continued, and returns whether it was
+// suspended by the prompt, which then needs to read the input again. The process may also have been
+// stopped from outside, leaving the prompt where it was.
+func (p *Prompt) resume() (suspended bool) {
+	suspended, p.suspended = p.suspended, false
+	debug.AssertNoError(p.in.Setup())
+	p.renderer.UpdateWinSize(p.in.GetWinSize())
+	p.setShown(true, p.renderer.resume)
+	p.renderer.setReading(true)
+	return suspended
+}
+
+// suspend leaves the input in the scrollback and restores what the prompt changed on the terminal.
+func (r *Render) suspend(buffer *Buffer) {
+	if r.mouse {
+		r.out.(MouseWriter).DisableMouse()
+	}
+	if r.fullScreen {
+		w := r.out.(FullScreenWriter)
+		w.ResetScrollingRegion()
+		w.ExitAlternateScreen()
+	} else if r.col != 0 {
+		r.paint(r.drawAccepted(buffer))
+		r.lineFeed()
+	}
+	r.forgetFrame()
+	debug.AssertNoError(r.flush())
+}
+
+// resume sets up the terminal again, the prompt being drawn from scratch where the cursor is.
+func (r *Render) resume() {
+	if r.fullScreen {
+		r.out.(FullScreenWriter).EnterAlternateScreen()
+		r.outputRows = 0
+	}
+	r.reportMouse(true)
+	r.forgetFrame()
+	debug.AssertNoError(r.flush())
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+// +build !windows
+
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSuspendAndResume(t *testing.T) {
+	w := &bufferWriter{}
+	r := &Render{
+		out:                w,
+		prefix:             "> ",
+		livePrefixCallback: func() (string, bool) { return "", false },
+		theme:              DefaultTheme(),
+		mouse:              true,
+		row:                20,
+		col:                40,
+	}
+	b := NewBuffer()
+	b.InsertText("sel", false, true)
+	c := NewCompletionManager(nil, 6)
+	c.SetResults([]Suggest{{Text: "select"}})
+	r.Render(b, c)
+
+	w.buffer = nil
+	r.suspend(b)
+	out := string(w.buffer)
+	if !strings.HasPrefix(out, "\x1b[?1006l\x1b[?1000l") {
+		t.Errorf("Should stop reporting the mouse, but got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[J") || !strings.HasSuffix(out, "\r\n") {
+		t.Errorf("Should erase the drop down and break the line, but got %q", out)
+	}
+
+	w.buffer = nil
+	r.resume()
+	r.Render(b, c)
+	out = string(w.buffer)
+	if !strings.HasPrefix(out, "\x1b[?1000h\x1b[?1006h") {
+		t.Errorf("Should report the mouse again, but got %q", out)
+	}
+	if !strings.Contains(out, "> ") || !strings.Contains(out, " select ") {
+		t.Errorf("Should draw the prompt from scratch, but got %q", out)
+	}
+}
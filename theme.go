@@ -0,0 +1,277 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Theme holds the style of every element drawn by the prompt.
+type Theme struct {
+	Prefix              Style
+	Input               Style
+	Placeholder         Style
+	PreviewSuggestion   Style
+	Suggestion          Style
+	SelectedSuggestion  Style
+	Description         Style
+	SelectedDescription Style
+	// Only the background color of the scrollbar styles is visible.
+	ScrollbarThumb Style
+	ScrollbarBG    Style
+	GroupHeader    Style
+	Documentation  Style
+	RPrompt        Style
+	// Warning is used for the message shown when the window is too small.
+	Warning Style
+	// Error is used for executor errors, and for the prefix after one.
+	// Only an ExecutorContext reports errors, see OptionExecutorContext.
+	Error Style
+}
+
+// DefaultTheme returns the classic go-prompt colors.
+func DefaultTheme() Theme {
+	return Theme{
+		Prefix:              Style{TextColor: Blue},
+		Placeholder:         Style{TextColor: Blue},
+		PreviewSuggestion:   Style{TextColor: Green},
+		Suggestion:          Style{TextColor: White, BGColor: Cyan},
+		SelectedSuggestion:  Style{TextColor: Black, BGColor: Turquoise, Bold: true},
+		Description:         Style{TextColor: Black, BGColor: Turquoise},
+		SelectedDescription: Style{TextColor: White, BGColor: Cyan},
+		ScrollbarThumb:      Style{BGColor: DarkGray},
+		ScrollbarBG:         Style{BGColor: Cyan},
+		GroupHeader:         Style{TextColor: White, BGColor: DarkBlue, Bold: true},
+		Documentation:       Style{TextColor: Black, BGColor: LightGray},
+		RPrompt:             Style{TextColor: DarkGray},
+		Warning:             Style{TextColor: DarkRed, BGColor: White},
+		Error:               Style{TextColor: Red},
+	}
+}
+
+// DarkTheme returns a theme for terminals with a dark background.
+func DarkTheme() Theme {
+	return Theme{
+		Prefix:              Style{TextColor: Turquoise, Bold: true},
+		Placeholder:         Style{TextColor: DarkGray},
+		PreviewSuggestion:   Style{TextColor: Green},
+		Suggestion:          Style{TextColor: LightGray, BGColor: Color256(236)},
+		SelectedSuggestion:  Style{TextColor: White, BGColor: Color256(24), Bold: true},
+		Description:         Style{TextColor: Color256(245), BGColor: Color256(235)},
+		SelectedDescription: Style{TextColor: LightGray, BGColor: Color256(24)},
+		ScrollbarThumb:      Style{BGColor: Color256(244)},
+		ScrollbarBG:         Style{BGColor: Color256(238)},
+		GroupHeader:         Style{TextColor: Yellow, BGColor: Color256(234), Bold: true},
+		Documentation:       Style{TextColor: LightGray, BGColor: Color256(234)},
+		RPrompt:             Style{TextColor: DarkGray},
+		Warning:             Style{TextColor: White, BGColor: DarkRed},
+		Error:               Style{TextColor: Red, Bold: true},
+	}
+}
+
+// LightTheme returns a theme for terminals with a light background.
+func LightTheme() Theme {
+	return Theme{
+		Prefix:              Style{TextColor: DarkBlue, Bold: true},
+		Placeholder:         Style{TextColor: DarkGray},
+		PreviewSuggestion:   Style{TextColor: DarkGreen},
+		Suggestion:          Style{TextColor: Black, BGColor: Color256(254)},
+		SelectedSuggestion:  Style{TextColor: White, BGColor: DarkBlue, Bold: true},
+		Description:         Style{TextColor: Color256(240), BGColor: Color256(253)},
+		SelectedDescription: Style{TextColor: White, BGColor: Color256(25)},
+		ScrollbarThumb:      Style{BGColor: Color256(244)},
+		ScrollbarBG:         Style{BGColor: Color256(251)},
+		GroupHeader:         Style{TextColor: DarkBlue, BGColor: Color256(252), Bold: true},
+		Documentation:       Style{TextColor: Black, BGColor: Color256(230)},
+		RPrompt:             Style{TextColor: Color256(244)},
+		Warning:             Style{TextColor: White, BGColor: DarkRed},
+		Error:               Style{TextColor: DarkRed, Bold: true},
+	}
+}
+
+// HighContrastTheme returns a theme that relies on black, white and reverse video only.
+func HighContrastTheme() Theme {
+	return Theme{
+		Prefix:              Style{TextColor: White, Bold: true},
+		Input:               Style{TextColor: White},
+		Placeholder:         Style{TextColor: White, Underline: true},
+		PreviewSuggestion:   Style{TextColor: White, Bold: true},
+		Suggestion:          Style{TextColor: White, BGColor: Black},
+		SelectedSuggestion:  Style{TextColor: Black, BGColor: White, Bold: true},
+		Description:         Style{TextColor: White, BGColor: Black},
+		SelectedDescription: Style{TextColor: Black, BGColor: White},
+		ScrollbarThumb:      Style{BGColor: White},
+		ScrollbarBG:         Style{BGColor: Black},
+		GroupHeader:         Style{TextColor: White, BGColor: Black, Bold: true, Underline: true},
+		Documentation:       Style{TextColor: White, BGColor: Black},
+		RPrompt:             Style{TextColor: White},
+		Warning:             Style{TextColor: Black, BGColor: White, Bold: true},
+		Error:               Style{TextColor: White, Bold: true, Underline: true},
+	}
+}
+
+// LoadThemeFile reads a theme from the file at path. See ParseTheme for the format.
+func LoadThemeFile(path string) (Theme, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	defer f.Close()
+	return ParseTheme(f)
+}
+
+// ParseTheme reads a theme made of "element = style" lines. For example:
+//
+//	# Start from a built-in theme: default, dark, light or high-contrast.
+//	base = dark
+//	prefix = green bold
+//	selected_suggestion = black on #ffaf00 bold
+//	description = 245 on 235 italic
+//
+// A style lists an optional text color, "on" followed by an optional background color,
+// and any of bold, italic, underline, reverse, dim and crossed_out.
+// Colors are ANSI color names like "darkred" or "turquoise", palette numbers from 0 to 255, or #rrggbb.
+// The elements are prefix, input, placeholder, preview_suggestion, suggestion, selected_suggestion,
+// description, selected_description, scrollbar_thumb, scrollbar_bg, group_header, documentation, rprompt, warning and error.
+// Elements which are not listed keep the style of the base theme, which defaults to DefaultTheme.
+func ParseTheme(r io.Reader) (Theme, error) {
+	t := DefaultTheme()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return Theme{}, fmt.Errorf("theme line %d: expected element = style", n)
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		value := strings.TrimSpace(kv[1])
+
+		if key == "base" {
+			base, ok := builtinThemes[strings.ToLower(value)]
+			if !ok {
+				return Theme{}, fmt.Errorf("theme line %d: unknown base theme %q", n, value)
+			}
+			t = base()
+			continue
+		}
+		s, ok := t.element(key)
+		if !ok {
+			return Theme{}, fmt.Errorf("theme line %d: unknown element %q", n, key)
+		}
+		style, err := parseStyle(value)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme line %d: %v", n, err)
+		}
+		*s = style
+	}
+	if err := scanner.Err(); err != nil {
+		return Theme{}, err
+	}
+	return t, nil
+}
+
+var builtinThemes = map[string]func() Theme{
+	"default":       DefaultTheme,
+	"dark":          DarkTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// element returns the style of t named key in theme files.
+func (t *Theme) element(key string) (*Style, bool) {
+	elements := map[string]*Style{
+		"prefix":               &t.Prefix,
+		"input":                &t.Input,
+		"placeholder":          &t.Placeholder,
+		"preview_suggestion":   &t.PreviewSuggestion,
+		"suggestion":           &t.Suggestion,
+		"selected_suggestion":  &t.SelectedSuggestion,
+		"description":          &t.Description,
+		"selected_description": &t.SelectedDescription,
+		"scrollbar_thumb":      &t.ScrollbarThumb,
+		"scrollbar_bg":         &t.ScrollbarBG,
+		"group_header":         &t.GroupHeader,
+		"documentation":        &t.Documentation,
+		"rprompt":              &t.RPrompt,
+		"warning":              &t.Warning,
+		"error":                &t.Error,
+	}
+	s, ok := elements[key]
+	return s, ok
+}
+
+func parseStyle(value string) (Style, error) {
+	var s Style
+	background := false
+	for _, word := range strings.Fields(strings.ToLower(value)) {
+		switch word {
+		case "on":
+			background = true
+		case "bold":
+			s.Bold = true
+		case "italic":
+			s.Italic = true
+		case "underline":
+			s.Underline = true
+		case "reverse":
+			s.Reverse = true
+		case "dim":
+			s.Dim = true
+		case "crossed_out":
+			s.CrossedOut = true
+		default:
+			c, err := parseColor(word)
+			if err != nil {
+				return Style{}, err
+			}
+			if background {
+				s.BGColor = c
+			} else {
+				s.TextColor = c
+			}
+		}
+	}
+	return s, nil
+}
+
+var colorNames = map[string]Color{
+	"default":   DefaultColor,
+	"black":     Black,
+	"darkred":   DarkRed,
+	"darkgreen": DarkGreen,
+	"brown":     Brown,
+	"darkblue":  DarkBlue,
+	"purple":    Purple,
+	"cyan":      Cyan,
+	"lightgray": LightGray,
+	"darkgray":  DarkGray,
+	"red":       Red,
+	"green":     Green,
+	"yellow":    Yellow,
+	"blue":      Blue,
+	"fuchsia":   Fuchsia,
+	"turquoise": Turquoise,
+	"white":     White,
+}
+
+func parseColor(word string) (Color, error) {
+	if c, ok := colorNames[word]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(word, "#") && len(word) == 7 {
+		v, err := strconv.ParseUint(word[1:], 16, 32)
+		if err == nil {
+			return ColorRGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+		}
+	}
+	if n, err := strconv.ParseUint(word, 10, 8); err == nil {
+		return Color256(uint8(n)), nil
+	}
+	return DefaultColor, fmt.Errorf("unknown color or attribute %q", word)
+}
@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTheme(t *testing.T) {
+	theme, err := ParseTheme(strings.NewReader(`
+# a comment
+base = light
+prefix = green bold
+selected_suggestion = black on #ffaf00 bold underline
+description = 245 on 235 italic
+scrollbar_thumb = on default
+`))
+	if err != nil {
+		t.Fatalf("Should parse the theme, but got %v", err)
+	}
+
+	expected := LightTheme()
+	expected.Prefix = Style{TextColor: Green, Bold: true}
+	expected.SelectedSuggestion = Style{TextColor: Black, BGColor: ColorRGB(0xff, 0xaf, 0x00), Bold: true, Underline: true}
+	expected.Description = Style{TextColor: Color256(245), BGColor: Color256(235), Italic: true}
+	expected.ScrollbarThumb = Style{}
+	if theme != expected {
+		t.Errorf("Should be %#v, but got %#v", expected, theme)
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	scenarioTable := []struct {
+		in       string
+		expected string
+	}{
+		{in: "prefix", expected: "theme line 1: expected element = style"},
+		{in: "\nbase = solarized", expected: `theme line 2: unknown base theme "solarized"`},
+		{in: "cursor = red", expected: `theme line 1: unknown element "cursor"`},
+		{in: "prefix = blink", expected: `theme line 1: unknown color or attribute "blink"`},
+	}
+
+	for _, s := range scenarioTable {
+		_, err := ParseTheme(strings.NewReader(s.in))
+		if err == nil || err.Error() != s.expected {
+			t.Errorf("Should fail with %q, but got %v", s.expected, err)
+		}
+	}
+}